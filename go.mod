@@ -4,6 +4,8 @@ go 1.20
 
 require (
 	github.com/alecthomas/kong v0.8.1
+	github.com/gogo/protobuf v1.3.2
+	github.com/golang/snappy v0.0.4
 	github.com/jonboulle/clockwork v0.4.0
 	github.com/mmihic/golib v0.1.21
 	github.com/mmihic/httplib v0.1.0
@@ -13,6 +15,7 @@ require (
 	go.uber.org/atomic v1.11.0
 	go.uber.org/zap v1.26.0
 	golang.org/x/sync v0.7.0
+	golang.org/x/time v0.5.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
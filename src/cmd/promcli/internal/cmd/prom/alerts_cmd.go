@@ -0,0 +1,55 @@
+package prom
+
+import (
+	"context"
+	"time"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+	"github.com/prometheus/common/model"
+
+	"github.com/mmihic/promlib/src/pkg/prom"
+)
+
+// AlertsQuery lists the active alerts.
+type AlertsQuery struct {
+	BaseCommand
+	State prom.AlertState `help:"only return alerts in this state (pending, firing)"`
+}
+
+func (cmd *AlertsQuery) Run(ctx context.Context) error {
+	c, err := cmd.PromClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := c.Alerts()
+	if cmd.State != "" {
+		q = q.State(cmd.State)
+	}
+
+	results, err := q.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format != cli.FormatCSV && cmd.Format != FormatTable {
+		return cmd.WriteOutput(results)
+	}
+
+	var (
+		headers = []string{"alertname", "state", "active_at", "value", "labels"}
+		rows    = make([][]string, 0, len(results))
+	)
+
+	for _, alert := range results {
+		rows = append(rows, []string{
+			string(alert.Labels[model.AlertNameLabel]),
+			string(alert.State),
+			alert.ActiveAt.UTC().Format(time.RFC3339),
+			alert.Value,
+			alert.Labels.String(),
+		})
+	}
+
+	return cmd.WriteTable(headers, rows)
+}
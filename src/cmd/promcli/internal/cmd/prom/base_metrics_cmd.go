@@ -2,15 +2,23 @@ package prom
 
 import (
 	"encoding/csv"
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/mmihic/golib/src/pkg/cli"
+	"go.uber.org/zap"
 
 	"github.com/mmihic/promlib/src/pkg/prom"
 	"github.com/mmihic/promlib/src/pkg/prom/promcli"
 )
 
+// FormatTable renders tabular results as aligned, human-readable columns.
+const FormatTable cli.Format = "table"
+
 // BaseCommand is the base command for all prom CLI options.
 type BaseCommand struct {
 	cli.FormattedOutput
@@ -18,13 +26,28 @@ type BaseCommand struct {
 	promcli.ClientOptions
 }
 
-// WriteResult writes the results of a query.
+// WriteResult writes the results of a query. When the result contains
+// native histograms, CSV output gains count, sum and buckets columns, with
+// the value column left empty for histogram samples.
 func (cmd *BaseCommand) WriteResult(result *prom.Result) error {
+	cmd.LogWarnings(result.Warnings)
 	if cmd.Format != cli.FormatCSV {
 		return cmd.WriteOutput(result)
 	}
 
+	hasHistograms := false
+	for iter := result.ValueIter(); iter.Next(); {
+		if iter.IsHistogram() {
+			hasHistograms = true
+			break
+		}
+	}
+
 	var headers = []string{"metric", "timestamp", "value"}
+	if hasHistograms {
+		headers = append(headers, "count", "sum", "buckets")
+	}
+
 	return cmd.WriteOutput(func(w io.Writer) error {
 		csvw := csv.NewWriter(w)
 		defer csvw.Flush()
@@ -34,11 +57,21 @@ func (cmd *BaseCommand) WriteResult(result *prom.Result) error {
 
 		iter := result.ValueIter()
 		for iter.Next() {
-			if err := csvw.Write([]string{
+			row := []string{
 				iter.Metric().String(),
 				iter.Timestamp().Format(time.RFC3339),
 				iter.StringValue(),
-			}); err != nil {
+			}
+
+			if iter.IsHistogram() {
+				row[2] = ""
+			}
+
+			if hasHistograms {
+				row = append(row, histogramColumns(iter)...)
+			}
+
+			if err := csvw.Write(row); err != nil {
 				return err
 			}
 		}
@@ -46,3 +79,102 @@ func (cmd *BaseCommand) WriteResult(result *prom.Result) error {
 		return nil
 	})
 }
+
+// histogramColumns returns the count, sum and buckets columns for the
+// current element, which are empty if it is not a histogram.
+func histogramColumns(iter prom.ValueIter) []string {
+	h := iter.HistogramValue()
+	if h == nil {
+		return []string{"", "", ""}
+	}
+
+	buckets := make([]string, 0, len(h.Buckets))
+	for _, b := range h.Buckets {
+		buckets = append(buckets, b.String())
+	}
+
+	return []string{h.Count.String(), h.Sum.String(), strings.Join(buckets, " ")}
+}
+
+// WriteTable writes rows of results as CSV or as an aligned table,
+// depending on the requested format.
+func (cmd *BaseCommand) WriteTable(headers []string, rows [][]string) error {
+	if cmd.Format == FormatTable {
+		return cmd.WriteOutput(func(w io.Writer) error {
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			if _, err := io.WriteString(tw, strings.ToUpper(strings.Join(headers, "\t"))+"\n"); err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				if _, err := io.WriteString(tw, strings.Join(row, "\t")+"\n"); err != nil {
+					return err
+				}
+			}
+
+			return tw.Flush()
+		})
+	}
+
+	return cmd.WriteOutput(func(w io.Writer) error {
+		csvw := csv.NewWriter(w)
+		defer csvw.Flush()
+		if err := csvw.Write(headers); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := csvw.Write(row); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// LogWarnings logs any warnings reported by the server, which would
+// otherwise be lost in CSV output. Truncated results are called out so
+// that the limit can be raised.
+func (cmd *BaseCommand) LogWarnings(warnings prom.Warnings) {
+	for _, warning := range warnings {
+		cmd.Log.Warn("query warning", zap.String("warning", warning))
+	}
+
+	if warnings.Truncated() {
+		cmd.Log.Warn("results were truncated, raise the limit to see all results")
+	}
+}
+
+// WriteStats writes a human-readable summary of query statistics.
+func WriteStats(w io.Writer, stats *prom.Stats) error {
+	if stats == nil {
+		_, err := io.WriteString(w, "no query statistics returned\n")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, stat := range []struct {
+		name  string
+		value string
+	}{
+		{"total queryable samples", strconv.FormatInt(stats.Samples.TotalQueryableSamples, 10)},
+		{"peak samples", strconv.FormatInt(stats.Samples.PeakSamples, 10)},
+		{"exec total time", formatSeconds(stats.Timings.ExecTotalTime)},
+		{"exec queue time", formatSeconds(stats.Timings.ExecQueueTime)},
+		{"eval total time", formatSeconds(stats.Timings.EvalTotalTime)},
+		{"inner eval time", formatSeconds(stats.Timings.InnerEvalTime)},
+		{"query preparation time", formatSeconds(stats.Timings.QueryPreparationTime)},
+		{"result sort time", formatSeconds(stats.Timings.ResultSortTime)},
+	} {
+		if _, err := fmt.Fprintf(tw, "%s:\t%s\n", stat.name, stat.value); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+func formatSeconds(secs float64) string {
+	return time.Duration(secs * float64(time.Second)).String()
+}
@@ -0,0 +1,58 @@
+package prom
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+
+	"github.com/mmihic/promlib/src/pkg/prom"
+)
+
+// Cardinality renders TSDB cardinality statistics, for chasing down
+// metrics and labels responsible for series growth.
+type Cardinality struct {
+	BaseCommand
+	Limit int `help:"number of entries to return for each statistic" default:"10"`
+}
+
+func (cmd *Cardinality) Run(ctx context.Context) error {
+	c, err := cmd.PromClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	results, err := c.TSDBStatus().Limit(cmd.Limit).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format != cli.FormatCSV && cmd.Format != FormatTable {
+		return cmd.WriteOutput(results)
+	}
+
+	var (
+		headers = []string{"stat", "name", "value"}
+		rows    = [][]string{
+			{"head", "num_series", strconv.FormatUint(results.HeadStats.NumSeries, 10)},
+			{"head", "num_label_pairs", strconv.Itoa(results.HeadStats.NumLabelPairs)},
+			{"head", "chunk_count", strconv.FormatInt(results.HeadStats.ChunkCount, 10)},
+		}
+	)
+
+	for _, stats := range []struct {
+		name  string
+		stats []prom.TSDBStat
+	}{
+		{"series_by_metric_name", results.SeriesCountByMetricName},
+		{"series_by_label_pair", results.SeriesCountByLabelValuePair},
+		{"values_by_label_name", results.LabelValueCountByLabelName},
+		{"memory_bytes_by_label_name", results.MemoryInBytesByLabelName},
+	} {
+		for _, stat := range stats.stats {
+			rows = append(rows, []string{stats.name, stat.Name, strconv.FormatUint(stat.Value, 10)})
+		}
+	}
+
+	return cmd.WriteTable(headers, rows)
+}
@@ -0,0 +1,96 @@
+package prom
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mmihic/promlib/src/pkg/prom/promcli"
+)
+
+// Config manages the named contexts in the promcli config file.
+type Config struct {
+	Use  ConfigUse  `cmd:"" help:"sets the current context"`
+	List ConfigList `cmd:"" help:"lists the contexts, marking the current one"`
+	View ConfigView `cmd:"" help:"shows the config file, or a single context"`
+}
+
+// ConfigUse sets the current context.
+type ConfigUse struct {
+	Name string `arg:"" help:"name of the context to use"`
+}
+
+// Run runs the command.
+func (cmd *ConfigUse) Run(cfg *promcli.Config) error {
+	if err := cfg.Use(cmd.Name); err != nil {
+		return err
+	}
+
+	return cfg.Save()
+}
+
+// ConfigList lists the contexts in the config file.
+type ConfigList struct {
+	cli.Output
+}
+
+// Run runs the command.
+func (cmd *ConfigList) Run(cfg *promcli.Config) error {
+	return cmd.WriteOutput(func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if _, err := io.WriteString(tw, "CURRENT\tNAME\tBACKEND\tSERVER\tTENANT\n"); err != nil {
+			return err
+		}
+
+		for _, name := range cfg.ContextNames() {
+			var (
+				ctx     = cfg.Contexts[name]
+				current = ""
+			)
+
+			if name == cfg.CurrentContext {
+				current = "*"
+			}
+
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				current, name, ctx.Backend, ctx.ServerURL, ctx.Tenant); err != nil {
+				return err
+			}
+		}
+
+		return tw.Flush()
+	})
+}
+
+// ConfigView shows the config file as YAML.
+type ConfigView struct {
+	cli.Output
+
+	Name string `arg:"" optional:"" help:"name of the context to show; shows the whole file if not set"`
+}
+
+// Run runs the command.
+func (cmd *ConfigView) Run(cfg *promcli.Config) error {
+	var v any = cfg
+	if cmd.Name != "" {
+		ctx, err := cfg.Context(cmd.Name)
+		if err != nil {
+			return err
+		}
+
+		v = ctx
+	}
+
+	return cmd.WriteOutput(func(w io.Writer) error {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	})
+}
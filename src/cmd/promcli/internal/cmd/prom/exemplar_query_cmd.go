@@ -0,0 +1,69 @@
+package prom
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"time"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+
+	"github.com/mmihic/promlib/src/pkg/prom/promcli"
+)
+
+// ExemplarQuery pulls exemplars for the series selected by a query.
+type ExemplarQuery struct {
+	BaseCommand
+	Start promcli.Time `help:"start date for the query"`
+	End   promcli.Time `help:"end date for the query"`
+	Query string       `short:"q" help:"query to run" required:""`
+}
+
+func (cmd *ExemplarQuery) Run(ctx context.Context) error {
+	c, err := cmd.PromClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := c.ExemplarQuery(cmd.Query)
+	if !cmd.Start.AsTime().IsZero() {
+		q = q.Start(cmd.Start.AsTime())
+	}
+
+	if !cmd.End.AsTime().IsZero() {
+		q = q.End(cmd.End.AsTime())
+	}
+
+	results, err := q.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format != cli.FormatCSV {
+		return cmd.WriteOutput(results)
+	}
+
+	var headers = []string{"series", "labels", "timestamp", "value"}
+	return cmd.WriteOutput(func(w io.Writer) error {
+		csvw := csv.NewWriter(w)
+		defer csvw.Flush()
+		if err := csvw.Write(headers); err != nil {
+			return err
+		}
+
+		for _, series := range results {
+			for _, exemplar := range series.Exemplars {
+				if err := csvw.Write([]string{
+					series.SeriesLabels.String(),
+					exemplar.Labels.String(),
+					exemplar.Timestamp.Time().Format(time.RFC3339),
+					exemplar.Value.String(),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
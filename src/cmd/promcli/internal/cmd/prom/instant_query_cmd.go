@@ -2,6 +2,8 @@ package prom
 
 import (
 	"context"
+	"os"
+	"time"
 
 	"github.com/mmihic/promlib/src/pkg/prom/promcli"
 )
@@ -9,8 +11,10 @@ import (
 // InstantQuery runs an instant query.
 type InstantQuery struct {
 	BaseCommand
-	Time  promcli.Time `help:"the time to query, defaults to now"`
-	Query string       `short:"q" required:"" help:"query to run"`
+	Time    promcli.Time     `help:"the time to query, defaults to now"`
+	Query   string           `short:"q" required:"" help:"query to run"`
+	Timeout promcli.Duration `help:"server-side evaluation timeout"`
+	Stats   bool             `help:"request query statistics and print them to stderr"`
 }
 
 // Run runs the command.
@@ -25,10 +29,24 @@ func (cmd *InstantQuery) Run(ctx context.Context) error {
 		q = q.Time(cmd.Time.AsTime())
 	}
 
+	if cmd.Timeout != 0 {
+		q = q.Timeout(time.Duration(cmd.Timeout))
+	}
+
+	if cmd.Stats {
+		q = q.WithStats()
+	}
+
 	result, err := q.Do(ctx)
 	if err != nil {
 		return err
 	}
 
+	if cmd.Stats {
+		if err := WriteStats(os.Stderr, result.Stats); err != nil {
+			return err
+		}
+	}
+
 	return cmd.WriteResult(result)
 }
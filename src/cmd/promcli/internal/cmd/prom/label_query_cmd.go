@@ -16,6 +16,7 @@ type LabelQuery struct {
 	Start promcli.Time `help:"start date for the query"`
 	End   promcli.Time `help:"end date for the query"`
 	Sel   []string     `help:"query to run"`
+	Limit int          `help:"maximum number of labels to return"`
 }
 
 func (cmd *LabelQuery) Run(ctx context.Context) error {
@@ -33,12 +34,18 @@ func (cmd *LabelQuery) Run(ctx context.Context) error {
 		q = q.End(cmd.End.AsTime())
 	}
 
+	if cmd.Limit != 0 {
+		q = q.Limit(cmd.Limit)
+	}
+
 	q = q.Selectors(cmd.Sel)
-	results, err := q.Do(ctx)
+	results, warnings, err := q.DoWithWarnings(ctx)
 	if err != nil {
 		return err
 	}
 
+	cmd.LogWarnings(warnings)
+
 	if cmd.Format != cli.FormatCSV {
 		return cmd.WriteOutput(results)
 	}
@@ -0,0 +1,70 @@
+package prom
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+
+	"github.com/mmihic/promlib/src/pkg/prom/promcli"
+)
+
+// LabelValuesQuery pulls the values of a label across series matching a set of selectors.
+type LabelValuesQuery struct {
+	BaseCommand
+	Label string       `arg:"" help:"name of the label"`
+	Start promcli.Time `help:"start date for the query"`
+	End   promcli.Time `help:"end date for the query"`
+	Sel   []string     `help:"selectors for series to consider"`
+	Limit int          `help:"maximum number of values to return"`
+}
+
+func (cmd *LabelValuesQuery) Run(ctx context.Context) error {
+	c, err := cmd.PromClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := c.LabelValuesQuery(cmd.Label)
+	if !cmd.Start.AsTime().IsZero() {
+		q = q.Start(cmd.Start.AsTime())
+	}
+
+	if !cmd.End.AsTime().IsZero() {
+		q = q.End(cmd.End.AsTime())
+	}
+
+	if cmd.Limit != 0 {
+		q = q.Limit(cmd.Limit)
+	}
+
+	q = q.Selectors(cmd.Sel)
+	results, warnings, err := q.DoWithWarnings(ctx)
+	if err != nil {
+		return err
+	}
+
+	cmd.LogWarnings(warnings)
+
+	if cmd.Format != cli.FormatCSV {
+		return cmd.WriteOutput(results)
+	}
+
+	var headers = []string{cmd.Label}
+	return cmd.WriteOutput(func(w io.Writer) error {
+		csvw := csv.NewWriter(w)
+		defer csvw.Flush()
+		if err := csvw.Write(headers); err != nil {
+			return err
+		}
+
+		for _, value := range results {
+			if err := csvw.Write([]string{value}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
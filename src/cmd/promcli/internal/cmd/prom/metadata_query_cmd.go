@@ -0,0 +1,72 @@
+package prom
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+)
+
+// MetadataQuery pulls the type, help and unit of metrics.
+type MetadataQuery struct {
+	BaseCommand
+	Metric         string `help:"metric to return metadata for, defaults to all metrics"`
+	Limit          int    `help:"maximum number of metrics to return"`
+	LimitPerMetric int    `help:"maximum number of metadata entries to return per metric"`
+}
+
+func (cmd *MetadataQuery) Run(ctx context.Context) error {
+	c, err := cmd.PromClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := c.MetadataQuery()
+	if cmd.Metric != "" {
+		q = q.Metric(cmd.Metric)
+	}
+
+	if cmd.Limit != 0 {
+		q = q.Limit(cmd.Limit)
+	}
+
+	if cmd.LimitPerMetric != 0 {
+		q = q.LimitPerMetric(cmd.LimitPerMetric)
+	}
+
+	results, err := q.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format != cli.FormatCSV {
+		return cmd.WriteOutput(results)
+	}
+
+	metrics := make([]string, 0, len(results))
+	for metric := range results {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	var headers = []string{"metric", "type", "help", "unit"}
+	return cmd.WriteOutput(func(w io.Writer) error {
+		csvw := csv.NewWriter(w)
+		defer csvw.Flush()
+		if err := csvw.Write(headers); err != nil {
+			return err
+		}
+
+		for _, metric := range metrics {
+			for _, md := range results[metric] {
+				if err := csvw.Write([]string{metric, string(md.Type), md.Help, md.Unit}); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
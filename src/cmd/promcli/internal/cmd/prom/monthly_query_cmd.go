@@ -13,6 +13,8 @@ type MonthlyQuery struct {
 	Start timex.MonthYear `help:"start date for the query"`
 	End   timex.MonthYear `help:"end date for the query"`
 	Query string          `short:"q" help:"query to run" required:""`
+
+	AtMonthEnd bool `help:"evaluate as an instant query at each month end, substituting $__range with the month length"`
 }
 
 // Run runs the command.
@@ -22,10 +24,15 @@ func (cmd *MonthlyQuery) Run(ctx context.Context) error {
 		return err
 	}
 
-	result, err := client.MonthlyQuery(cmd.Query).
+	q := client.MonthlyQuery(cmd.Query).
 		Start(cmd.Start).
-		End(cmd.End).
-		Do(ctx)
+		End(cmd.End)
+
+	if cmd.AtMonthEnd {
+		q = q.AtMonthEnd()
+	}
+
+	result, err := q.Do(ctx)
 	if err != nil {
 		return err
 	}
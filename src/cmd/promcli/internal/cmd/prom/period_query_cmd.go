@@ -0,0 +1,44 @@
+package prom
+
+import (
+	"context"
+	"time"
+
+	"github.com/mmihic/promlib/src/pkg/prom"
+	"github.com/mmihic/promlib/src/pkg/prom/promcli"
+)
+
+// PeriodQuery runs a range query over calendar periods.
+type PeriodQuery struct {
+	BaseCommand
+
+	Period   prom.Period  `required:"" help:"period to bucket by (day, week, month, quarter, year)"`
+	Start    promcli.Time `required:"" help:"start date for the query"`
+	End      promcli.Time `required:"" help:"end date for the query"`
+	TimeZone string       `name:"tz" help:"time zone used for period boundaries" default:"UTC"`
+	Query    string       `short:"q" help:"query to run" required:""`
+}
+
+// Run runs the command.
+func (cmd *PeriodQuery) Run(ctx context.Context) error {
+	loc, err := time.LoadLocation(cmd.TimeZone)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.PromClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.PeriodQuery(cmd.Query, cmd.Period).
+		In(loc).
+		Start(cmd.Start.AsTime()).
+		End(cmd.End.AsTime()).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(result)
+}
@@ -2,7 +2,15 @@ package prom
 
 import (
 	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"time"
 
+	"github.com/mmihic/golib/src/pkg/cli"
+
+	"github.com/mmihic/promlib/src/pkg/prom"
 	"github.com/mmihic/promlib/src/pkg/prom/promcli"
 )
 
@@ -14,6 +22,14 @@ type RangeQuery struct {
 	End   promcli.Time     `help:"end date for the query"`
 	Step  promcli.Duration `help:"step function"`
 	Query string           `short:"q" help:"query to run" required:""`
+
+	SplitBy     promcli.Duration `help:"split the query into sub-ranges of this duration"`
+	AutoSplit   bool             `help:"split the query if it would exceed the server's points-per-series limit"`
+	MaxParallel int              `help:"maximum number of sub-range queries to run in parallel"`
+
+	Timeout promcli.Duration `help:"server-side evaluation timeout"`
+	Stats   bool             `help:"request query statistics and print them to stderr"`
+	Stream  bool             `help:"write CSV rows as each series is received rather than buffering the result"`
 }
 
 // Run runs the command.
@@ -31,10 +47,85 @@ func (cmd *RangeQuery) Run(ctx context.Context) error {
 		q = q.Step(cmd.Step.AsDuration())
 	}
 
+	switch {
+	case cmd.SplitBy != 0:
+		q = q.SplitBy(time.Duration(cmd.SplitBy))
+	case cmd.AutoSplit:
+		q = q.AutoSplit()
+	}
+
+	if cmd.MaxParallel != 0 {
+		q = q.MaxParallel(cmd.MaxParallel)
+	}
+
+	if cmd.Timeout != 0 {
+		q = q.Timeout(time.Duration(cmd.Timeout))
+	}
+
+	if cmd.Stats {
+		q = q.WithStats()
+	}
+
+	if cmd.Stream {
+		return cmd.stream(ctx, q)
+	}
+
 	result, err := q.Do(ctx)
 	if err != nil {
 		return err
 	}
 
+	if cmd.Stats {
+		if err := WriteStats(os.Stderr, result.Stats); err != nil {
+			return err
+		}
+	}
+
 	return cmd.WriteResult(result)
 }
+
+// stream runs the query, writing each series as CSV as soon as it is
+// decoded. Histogram samples are written in their string form, since
+// whether any are present is not known until the end of the response.
+func (cmd *RangeQuery) stream(ctx context.Context, q prom.RangeQuery) error {
+	if cmd.Format != cli.FormatCSV {
+		return fmt.Errorf("--stream requires --format=%s", cli.FormatCSV)
+	}
+
+	var result *prom.Result
+	err := cmd.WriteOutput(func(w io.Writer) error {
+		csvw := csv.NewWriter(w)
+		defer csvw.Flush()
+		if err := csvw.Write([]string{"metric", "timestamp", "value"}); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = q.Stream(ctx, func(iter prom.ValueIter) error {
+			for iter.Next() {
+				err := csvw.Write([]string{
+					iter.Metric().String(),
+					iter.Timestamp().Format(time.RFC3339),
+					iter.StringValue(),
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			csvw.Flush()
+			return csvw.Error()
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd.LogWarnings(result.Warnings)
+	if cmd.Stats {
+		return WriteStats(os.Stderr, result.Stats)
+	}
+
+	return nil
+}
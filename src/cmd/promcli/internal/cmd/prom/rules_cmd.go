@@ -0,0 +1,56 @@
+package prom
+
+import (
+	"context"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+
+	"github.com/mmihic/promlib/src/pkg/prom"
+)
+
+// RulesQuery lists the alerting and recording rules loaded by the server.
+type RulesQuery struct {
+	BaseCommand
+	Type prom.RuleType `help:"only return rules of this type (alert, record)"`
+}
+
+func (cmd *RulesQuery) Run(ctx context.Context) error {
+	c, err := cmd.PromClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := c.Rules()
+	if cmd.Type != "" {
+		q = q.Type(cmd.Type)
+	}
+
+	results, err := q.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format != cli.FormatCSV && cmd.Format != FormatTable {
+		return cmd.WriteOutput(results)
+	}
+
+	var (
+		headers = []string{"group", "name", "type", "health", "state", "query"}
+		rows    [][]string
+	)
+
+	for _, group := range results {
+		for _, rule := range group.Rules {
+			rows = append(rows, []string{
+				group.Name,
+				rule.Name,
+				rule.Type,
+				rule.Health,
+				string(rule.State),
+				rule.Query,
+			})
+		}
+	}
+
+	return cmd.WriteTable(headers, rows)
+}
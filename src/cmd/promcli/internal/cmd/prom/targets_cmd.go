@@ -0,0 +1,71 @@
+package prom
+
+import (
+	"context"
+	"time"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+
+	"github.com/mmihic/promlib/src/pkg/prom"
+)
+
+// TargetsQuery lists the scrape targets known to the server.
+type TargetsQuery struct {
+	BaseCommand
+	State      prom.TargetState `help:"only return targets in this state (active, dropped, any)"`
+	ScrapePool string           `help:"only return targets in this scrape pool"`
+}
+
+func (cmd *TargetsQuery) Run(ctx context.Context) error {
+	c, err := cmd.PromClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := c.Targets()
+	if cmd.State != "" {
+		q = q.State(cmd.State)
+	}
+
+	if cmd.ScrapePool != "" {
+		q = q.ScrapePool(cmd.ScrapePool)
+	}
+
+	results, err := q.Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format != cli.FormatCSV && cmd.Format != FormatTable {
+		return cmd.WriteOutput(results)
+	}
+
+	var (
+		headers = []string{"scrape_pool", "scrape_url", "health", "last_scrape", "last_error", "labels"}
+		rows    = make([][]string, 0, len(results.Active)+len(results.Dropped))
+	)
+
+	for _, target := range results.Active {
+		rows = append(rows, []string{
+			target.ScrapePool,
+			target.ScrapeURL,
+			string(target.Health),
+			target.LastScrape.UTC().Format(time.RFC3339),
+			target.LastError,
+			target.Labels.String(),
+		})
+	}
+
+	for _, target := range results.Dropped {
+		rows = append(rows, []string{
+			target.DiscoveredLabels["job"],
+			target.DiscoveredLabels["__address__"],
+			"dropped",
+			"",
+			"",
+			"",
+		})
+	}
+
+	return cmd.WriteTable(headers, rows)
+}
@@ -0,0 +1,190 @@
+package prom
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/mmihic/promlib/src/pkg/prom"
+	"github.com/mmihic/promlib/src/pkg/prom/promcli"
+)
+
+// Write pushes series into a Prometheus-compatible store through the remote
+// write API. The input is in the JSON or CSV format written by the query
+// commands, so the results of one query can be written back as new series.
+type Write struct {
+	cli.WithLogger
+	promcli.ClientOptions
+
+	Input       string     `arg:"" optional:"" help:"file to read series from, or - for stdin" default:"-"`
+	InputFormat cli.Format `help:"format of the input (json or csv); defaults to the input file extension, or json"`
+	BatchSize   int        `help:"maximum number of samples per request" default:"2000"`
+	Shards      int        `help:"number of requests to send in parallel" default:"4"`
+}
+
+// Run runs the command.
+func (cmd *Write) Run(ctx context.Context) error {
+	client, err := cmd.PromClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	m, err := cmd.readInput()
+	if err != nil {
+		return err
+	}
+
+	return client.RemoteWriter().
+		BatchSize(cmd.BatchSize).
+		Shards(cmd.Shards).
+		Write(ctx, m)
+}
+
+// readInput reads the series to write.
+func (cmd *Write) readInput() (model.Value, error) {
+	format := cmd.InputFormat
+	if format == cli.FormatUnknown {
+		format = cli.FormatJSON
+		if strings.EqualFold(filepath.Ext(cmd.Input), ".csv") {
+			format = cli.FormatCSV
+		}
+	}
+
+	var r io.Reader = os.Stdin
+	if cmd.Input != "" && cmd.Input != "-" {
+		f, err := os.Open(cmd.Input)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+
+		r = f
+	}
+
+	switch format {
+	case cli.FormatJSON:
+		var result prom.Result
+		if err := json.NewDecoder(r).Decode(&result); err != nil {
+			return nil, fmt.Errorf("unable to decode input: %w", err)
+		}
+
+		return result.Data, nil
+	case cli.FormatCSV:
+		return readCSVSeries(r)
+	default:
+		return nil, fmt.Errorf("unsupported input format '%s'", format)
+	}
+}
+
+// readCSVSeries reads series from CSV with metric, timestamp and value
+// columns, as written by BaseCommand.WriteResult.
+func readCSVSeries(r io.Reader) (model.Matrix, error) {
+	csvr := csv.NewReader(r)
+	csvr.FieldsPerRecord = -1
+
+	headers, err := csvr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, header := range headers {
+		columns[header] = i
+	}
+
+	for _, required := range []string{"metric", "timestamp", "value"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing '%s' column", required)
+		}
+	}
+
+	var (
+		m      model.Matrix
+		series = map[model.Fingerprint]*model.SampleStream{}
+	)
+
+	for line := 2; ; line++ {
+		row, err := csvr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(row) != len(headers) {
+			return nil, fmt.Errorf("line %d: expected %d columns, got %d", line, len(headers), len(row))
+		}
+
+		metric, sample, err := parseCSVSample(row[columns["metric"]], row[columns["timestamp"]], row[columns["value"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		ss, ok := series[metric.Fingerprint()]
+		if !ok {
+			ss = &model.SampleStream{Metric: metric}
+			series[metric.Fingerprint()] = ss
+			m = append(m, ss)
+		}
+
+		ss.Values = append(ss.Values, sample)
+	}
+
+	for _, ss := range m {
+		sort.SliceStable(ss.Values, func(i, j int) bool {
+			return ss.Values[i].Timestamp < ss.Values[j].Timestamp
+		})
+	}
+
+	return m, nil
+}
+
+// parseCSVSample parses the metric, timestamp and value columns of a row.
+func parseCSVSample(metricCol, timestampCol, valueCol string) (model.Metric, model.SamplePair, error) {
+	lbls, err := parser.ParseMetric(metricCol)
+	if err != nil {
+		return nil, model.SamplePair{}, fmt.Errorf("invalid metric %q: %w", metricCol, err)
+	}
+
+	metric := make(model.Metric, lbls.Len())
+	lbls.Range(func(l labels.Label) {
+		metric[model.LabelName(l.Name)] = model.LabelValue(l.Value)
+	})
+
+	ts, err := time.Parse(time.RFC3339, timestampCol)
+	if err != nil {
+		return nil, model.SamplePair{}, fmt.Errorf("invalid timestamp %q: %w", timestampCol, err)
+	}
+
+	if valueCol == "" {
+		return nil, model.SamplePair{}, errors.New("native histograms cannot be written")
+	}
+
+	value, err := strconv.ParseFloat(valueCol, 64)
+	if err != nil {
+		return nil, model.SamplePair{}, fmt.Errorf("invalid value %q: %w", valueCol, err)
+	}
+
+	return metric, model.SamplePair{
+		Timestamp: model.TimeFromUnixNano(ts.UnixNano()),
+		Value:     model.SampleValue(value),
+	}, nil
+}
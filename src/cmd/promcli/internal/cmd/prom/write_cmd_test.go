@@ -0,0 +1,110 @@
+package prom
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmihic/golib/src/pkg/cli"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/mmihic/promlib/src/pkg/prom"
+)
+
+// writeCSVResult writes the result as CSV through BaseCommand.WriteResult,
+// returning the name of the file it was written to.
+func writeCSVResult(t *testing.T, result *prom.Result) string {
+	cmd := &BaseCommand{
+		FormattedOutput: cli.FormattedOutput{
+			Format: cli.FormatCSV,
+			Output: cli.Output{Output: cli.OutputToTemp},
+		},
+		WithLogger: cli.WithLogger{Log: zap.NewNop()},
+	}
+
+	require.NoError(t, cmd.WriteResult(result))
+	t.Cleanup(func() {
+		_ = os.Remove(cmd.Output.Output)
+	})
+
+	return cmd.Output.Output
+}
+
+func readCSVFile(t *testing.T, name string) (model.Matrix, error) {
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return readCSVSeries(f)
+}
+
+func TestReadCSVSeries_RoundTrip(t *testing.T) {
+	var (
+		t0 = model.TimeFromUnix(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Unix())
+		t1 = t0.Add(time.Minute)
+	)
+
+	expected := model.Matrix{
+		{
+			Metric: model.Metric{"__name__": "up", "job": "api", "instance": "host:9090"},
+			Values: []model.SamplePair{{Timestamp: t0, Value: 1}, {Timestamp: t1, Value: 0}},
+		},
+		{
+			Metric: model.Metric{"__name__": "http_requests:rate5m", "path": `/api/"v1"`},
+			Values: []model.SamplePair{{Timestamp: t0, Value: 0.25}, {Timestamp: t1, Value: 1e-9}},
+		},
+	}
+
+	actual, err := readCSVFile(t, writeCSVResult(t, &prom.Result{Status: "success", Data: expected}))
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadCSVSeries_Histograms(t *testing.T) {
+	t0 := model.TimeFromUnix(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Unix())
+
+	floats := &model.SampleStream{
+		Metric: model.Metric{"__name__": "up"},
+		Values: []model.SamplePair{{Timestamp: t0, Value: 1}},
+	}
+
+	name := writeCSVResult(t, &prom.Result{Status: "success", Data: model.Matrix{
+		floats,
+		{
+			Metric: model.Metric{"__name__": "latency"},
+			Histograms: []model.SampleHistogramPair{{
+				Timestamp: t0,
+				Histogram: &model.SampleHistogram{
+					Count: 2,
+					Sum:   0.5,
+					Buckets: model.HistogramBuckets{
+						{Boundaries: 0, Lower: 0.1, Upper: 0.5, Count: 2},
+					},
+				},
+			}},
+		},
+	}})
+
+	// Histogram samples cannot be written, and are rejected
+	_, err := readCSVFile(t, name)
+	require.Error(t, err)
+	assert.Equal(t, "line 3: native histograms cannot be written", err.Error())
+
+	b, err := os.ReadFile(name)
+	require.NoError(t, err)
+
+	lines := strings.SplitAfter(string(b), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "metric,timestamp,value,count,sum,buckets\n", lines[0])
+
+	// Float samples are read from CSV that has the histogram columns
+	actual, err := readCSVSeries(strings.NewReader(lines[0] + lines[1]))
+	require.NoError(t, err)
+	assert.Equal(t, model.Matrix{floats}, actual)
+}
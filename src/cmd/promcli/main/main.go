@@ -8,14 +8,30 @@ import (
 	"github.com/alecthomas/kong"
 
 	"github.com/mmihic/promlib/src/cmd/promcli/internal/cmd/prom"
+	"github.com/mmihic/promlib/src/pkg/prom/promcli"
 )
 
 type Commands struct {
 	Instant prom.InstantQuery `cmd:"" help:"runs an instant query"`
 	Range   prom.RangeQuery   `cmd:"" help:"runs a range query"`
 	Monthly prom.MonthlyQuery `cmd:"" help:"runs a range query over months"`
+	Period  prom.PeriodQuery  `cmd:"" help:"runs a range query over calendar periods"`
 	Series  prom.SeriesQuery  `cmd:"" help:"pulls series matching an optional set of selectors"`
 	Labels  prom.LabelQuery   `cmd:"" help:"pulls label names matching an optional set of selectors"`
+
+	LabelValues prom.LabelValuesQuery `cmd:"" help:"pulls the values of a label matching an optional set of selectors"`
+	Metadata    prom.MetadataQuery    `cmd:"" help:"pulls the type, help and unit of metrics"`
+	Exemplars   prom.ExemplarQuery    `cmd:"" help:"pulls exemplars for the series selected by a query"`
+
+	Targets prom.TargetsQuery `cmd:"" help:"lists scrape targets"`
+	Rules   prom.RulesQuery   `cmd:"" help:"lists alerting and recording rules"`
+	Alerts  prom.AlertsQuery  `cmd:"" help:"lists active alerts"`
+
+	Cardinality prom.Cardinality `cmd:"" help:"shows TSDB cardinality statistics by metric name and label"`
+
+	Write prom.Write `cmd:"" help:"writes series from a query result through the remote write API"`
+
+	Config prom.Config `cmd:"" help:"manages named contexts in the config file"`
 }
 
 func main() {
@@ -24,9 +40,22 @@ func main() {
 		panic(err)
 	}
 
+	configFile, err := promcli.DefaultConfigFile()
+	if err != nil {
+		logger.Fatal("unable to locate config file", zap.Error(err))
+	}
+
+	// A broken config file only fails the commands that use it, so that the
+	// config commands can still be used to inspect it
+	config, err := promcli.LoadConfig(configFile)
+	if err != nil {
+		logger.Warn("unable to load config file, ignoring it", zap.Error(err))
+	}
+
 	var cli Commands
 	var k = kong.Parse(&cli,
-		kong.Bind(logger),
+		kong.Bind(logger, config),
+		kong.Resolvers(config.Resolver()),
 		kong.BindTo(context.Background(), (*context.Context)(nil)))
 	if err := k.Run(); err != nil {
 		logger.Fatal("unable to run command", zap.Error(err))
@@ -0,0 +1,88 @@
+package prom
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+// AlertState is the state of an alert.
+type AlertState string
+
+// Alert states.
+const (
+	AlertStateInactive AlertState = "inactive"
+	AlertStatePending  AlertState = "pending"
+	AlertStateFiring   AlertState = "firing"
+)
+
+// An Alert is an active alert.
+type Alert struct {
+	Labels      model.LabelSet `json:"labels" yaml:"labels"`
+	Annotations model.LabelSet `json:"annotations" yaml:"annotations"`
+	State       AlertState     `json:"state" yaml:"state"`
+	ActiveAt    time.Time      `json:"activeAt" yaml:"activeAt"`
+	Value       string         `json:"value" yaml:"value"`
+}
+
+// An AlertsQuery returns the active alerts.
+type AlertsQuery interface {
+	// State restricts the alerts to those in the given state. The server
+	// does not support filtering, so this is applied by the client.
+	State(state AlertState) AlertsQuery
+	Do(ctx context.Context) ([]Alert, error)
+}
+
+func (c *client) Alerts() AlertsQuery {
+	return alertsQuery{
+		c: c,
+	}
+}
+
+type alertsQuery struct {
+	c     *client
+	state AlertState
+}
+
+func (q alertsQuery) State(state AlertState) AlertsQuery {
+	q.state = state
+	return q
+}
+
+func (q alertsQuery) Do(ctx context.Context) ([]Alert, error) {
+	log := q.c.queryLog.BeginQuery("alerts-query",
+		zap.String("state", string(q.state)))
+
+	var r alertsResult
+	if err := q.c.get(ctx, log, pathAlertsQuery, nil, &r); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if err := envelopeErr(r.Status, r.ErrorType, r.Error); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	log.QueryComplete(&r)
+	return FilterAlerts(r.Data.Alerts, q.state), nil
+}
+
+// FilterAlerts returns the alerts in the given state, or all alerts if the
+// state is empty.
+func FilterAlerts(alerts []Alert, state AlertState) []Alert {
+	if state == "" {
+		return alerts
+	}
+
+	var filtered []Alert
+	for _, alert := range alerts {
+		if alert.State == state {
+			filtered = append(filtered, alert)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,292 @@
+package prom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mmihic/httplib/src/pkg/httplib"
+)
+
+// tokenExpiryMargin is how long before it expires that an OAuth2 token is
+// refreshed, so that it does not expire in flight.
+const tokenExpiryMargin = 30 * time.Second
+
+// An Authenticator adds credentials to each request made by the client.
+type Authenticator interface {
+	// Authenticate sets the credentials on the headers of a request. It is
+	// called before every attempt, so may refresh credentials as needed.
+	Authenticate(ctx context.Context, h http.Header) error
+}
+
+// WithAuth sets the Authenticator used to add credentials to requests.
+func WithAuth(auth Authenticator) ClientOpt {
+	return func(c *client) {
+		c.auth = auth
+	}
+}
+
+// authIdentity returns who an Authenticator authenticates as, so that
+// cached results are kept apart between callers. Rotating credentials are
+// identified by where they come from rather than their current value.
+// Custom Authenticators are only identified by their type.
+func authIdentity(auth Authenticator) string {
+	switch auth := auth.(type) {
+	case nil:
+		return ""
+	case basicAuth:
+		return "basic " + auth.username
+	case headerAuth:
+		return "header " + auth.name + ": " + auth.value
+	case *bearerTokenFile:
+		return "bearer token file " + auth.path
+	case *oauth2ClientCredentials:
+		return fmt.Sprintf("oauth2 %s %s %s %s", auth.cfg.TokenURL, auth.cfg.ClientID,
+			strings.Join(auth.cfg.Scopes, " "), auth.cfg.EndpointParams.Encode())
+	default:
+		return fmt.Sprintf("%T", auth)
+	}
+}
+
+// requestOptions returns the call options that add the client's headers
+// and credentials to a request made through the httplib client.
+func (c *client) requestOptions(ctx context.Context) ([]httplib.CallOption, error) {
+	h := c.headers.Clone()
+	if c.auth != nil {
+		if err := c.auth.Authenticate(ctx, h); err != nil {
+			return nil, fmt.Errorf("unable to authenticate: %w", err)
+		}
+	}
+
+	opts := make([]httplib.CallOption, 0, len(h))
+	for name := range h {
+		opts = append(opts, httplib.SetHeader(name, h.Get(name)))
+	}
+
+	return opts, nil
+}
+
+// BasicAuth authenticates with a username and password.
+func BasicAuth(username, password string) Authenticator {
+	return basicAuth{username: username, password: password}
+}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (auth basicAuth) Authenticate(_ context.Context, h http.Header) error {
+	req := http.Request{Header: h}
+	req.SetBasicAuth(auth.username, auth.password)
+	return nil
+}
+
+// BearerToken authenticates with a static bearer token.
+func BearerToken(token string) Authenticator {
+	return HeaderAuth("Authorization", "Bearer "+token)
+}
+
+// HeaderAuth authenticates with a fixed header, e.g. an API key.
+func HeaderAuth(name, value string) Authenticator {
+	return headerAuth{name: name, value: value}
+}
+
+type headerAuth struct {
+	name  string
+	value string
+}
+
+func (auth headerAuth) Authenticate(_ context.Context, h http.Header) error {
+	h.Set(auth.name, auth.value)
+	return nil
+}
+
+// BearerTokenFile authenticates with a bearer token read from a file,
+// re-reading the file whenever it changes so that rotated tokens are
+// picked up without restarting.
+func BearerTokenFile(path string) Authenticator {
+	return &bearerTokenFile{path: path}
+}
+
+type bearerTokenFile struct {
+	path string
+
+	mut     sync.Mutex
+	modTime time.Time
+	token   string
+}
+
+func (auth *bearerTokenFile) Authenticate(_ context.Context, h http.Header) error {
+	token, err := auth.read()
+	if err != nil {
+		return err
+	}
+
+	h.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// read returns the token, reloading the file if it has been modified.
+func (auth *bearerTokenFile) read() (string, error) {
+	info, err := os.Stat(auth.path)
+	if err != nil {
+		return "", err
+	}
+
+	auth.mut.Lock()
+	defer auth.mut.Unlock()
+
+	if auth.token != "" && info.ModTime().Equal(auth.modTime) {
+		return auth.token, nil
+	}
+
+	b, err := os.ReadFile(auth.path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("bearer token file %s is empty", auth.path)
+	}
+
+	auth.token, auth.modTime = token, info.ModTime()
+	return auth.token, nil
+}
+
+// OAuth2Config configures the OAuth2 client credentials flow.
+type OAuth2Config struct {
+	// TokenURL is the URL of the token endpoint.
+	TokenURL string
+
+	// ClientID and ClientSecret identify the client to the token endpoint.
+	ClientID     string
+	ClientSecret string
+
+	// Scopes are the optional scopes to request.
+	Scopes []string
+
+	// EndpointParams are additional parameters sent to the token endpoint,
+	// e.g. an audience.
+	EndpointParams url.Values
+
+	// HTTPClient is used to request tokens. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// OAuth2ClientCredentials authenticates with bearer tokens obtained through
+// the OAuth2 client credentials flow, fetching a new token shortly before
+// the current one expires.
+func OAuth2ClientCredentials(cfg OAuth2Config) Authenticator {
+	return OAuth2ClientCredentialsWithClock(cfg, clockwork.NewRealClock())
+}
+
+// OAuth2ClientCredentialsWithClock is OAuth2ClientCredentials with an
+// explicit clock for expiring tokens.
+func OAuth2ClientCredentialsWithClock(cfg OAuth2Config, clock clockwork.Clock) Authenticator {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &oauth2ClientCredentials{cfg: cfg, clock: clock}
+}
+
+type oauth2ClientCredentials struct {
+	cfg   OAuth2Config
+	clock clockwork.Clock
+
+	mut     sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (auth *oauth2ClientCredentials) Authenticate(ctx context.Context, h http.Header) error {
+	auth.mut.Lock()
+	defer auth.mut.Unlock()
+
+	if auth.token == "" || (!auth.expires.IsZero() && !auth.clock.Now().Before(auth.expires)) {
+		if err := auth.refresh(ctx); err != nil {
+			return err
+		}
+	}
+
+	h.Set("Authorization", "Bearer "+auth.token)
+	return nil
+}
+
+// refresh fetches a new token from the token endpoint.
+func (auth *oauth2ClientCredentials) refresh(ctx context.Context) error {
+	p := url.Values{}
+	for name, values := range auth.cfg.EndpointParams {
+		p[name] = values
+	}
+
+	p.Set("grant_type", "client_credentials")
+	if len(auth.cfg.Scopes) != 0 {
+		p.Set("scope", strings.Join(auth.cfg.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, auth.cfg.TokenURL, strings.NewReader(p.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(auth.cfg.ClientID), url.QueryEscape(auth.cfg.ClientSecret))
+
+	resp, err := auth.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to fetch OAuth2 token: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return fmt.Errorf("unable to fetch OAuth2 token: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unable to fetch OAuth2 token: %w", NewError(resp.StatusCode, string(b)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	if err := json.Unmarshal(b, &token); err != nil {
+		return fmt.Errorf("unable to decode OAuth2 token: %w", err)
+	}
+
+	if token.AccessToken == "" {
+		return errors.New("OAuth2 token response has no access_token")
+	}
+
+	auth.token, auth.expires = token.AccessToken, time.Time{}
+	if token.ExpiresIn > 0 {
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		margin := tokenExpiryMargin
+		if margin > lifetime/2 {
+			margin = lifetime / 2
+		}
+
+		auth.expires = auth.clock.Now().Add(lifetime - margin)
+	}
+
+	return nil
+}
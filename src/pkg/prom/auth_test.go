@@ -0,0 +1,134 @@
+package prom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAuth_BasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "admin" || password != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"status": "success", "data": ["job"]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithAuth(BasicAuth("admin", "s3cret")))
+	require.NoError(t, err)
+
+	labels, err := c.LabelQuery().Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job"}, labels)
+
+	c, err = NewClient(srv.URL, WithAuth(BasicAuth("admin", "wrong")))
+	require.NoError(t, err)
+
+	_, err = c.LabelQuery().Do(context.TODO())
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestBearerTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+
+	auth := BearerTokenFile(path)
+	h := http.Header{}
+	require.NoError(t, auth.Authenticate(context.TODO(), h))
+	assert.Equal(t, "Bearer first", h.Get("Authorization"))
+
+	// Rotating the token is picked up on the next request
+	require.NoError(t, os.WriteFile(path, []byte("second\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	require.NoError(t, auth.Authenticate(context.TODO(), h))
+	assert.Equal(t, "Bearer second", h.Get("Authorization"))
+
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, auth.Authenticate(context.TODO(), h))
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	var fetches atomic.Int32
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "promlib", clientID)
+		assert.Equal(t, "s3cret", secret)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+		assert.Equal(t, "prom", r.PostForm.Get("audience"))
+
+		if fetches.Add(1) == 1 {
+			_, _ = w.Write([]byte(`{"access_token": "token-1", "token_type": "bearer", "expires_in": 3600}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"access_token": "token-2", "token_type": "bearer", "expires_in": 3600}`))
+	}))
+	defer tokenSrv.Close()
+
+	clock := clockwork.NewFakeClock()
+	auth := OAuth2ClientCredentialsWithClock(OAuth2Config{
+		TokenURL:       tokenSrv.URL,
+		ClientID:       "promlib",
+		ClientSecret:   "s3cret",
+		Scopes:         []string{"read", "write"},
+		EndpointParams: map[string][]string{"audience": {"prom"}},
+	}, clock)
+
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "matrix", "result": []}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithAuth(auth))
+	require.NoError(t, err)
+
+	stream := func() {
+		_, err := c.RangeQuery("up").
+			Start(time.Unix(0, 0)).
+			End(time.Unix(60, 0)).
+			Stream(context.TODO(), func(iter ValueIter) error { return nil })
+		require.NoError(t, err)
+	}
+
+	// The token is cached until shortly before it expires
+	stream()
+	clock.Advance(time.Hour - 2*tokenExpiryMargin)
+	stream()
+	clock.Advance(tokenExpiryMargin)
+	stream()
+
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}, seen)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestOAuth2ClientCredentials_Errors(t *testing.T) {
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "invalid_client"}`, http.StatusUnauthorized)
+	}))
+	defer tokenSrv.Close()
+
+	auth := OAuth2ClientCredentials(OAuth2Config{TokenURL: tokenSrv.URL, ClientID: "promlib"})
+	err := auth.Authenticate(context.TODO(), http.Header{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid_client")
+}
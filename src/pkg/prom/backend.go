@@ -0,0 +1,188 @@
+package prom
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	chronosphereURL = "https://%s.chronosphere.io/data/m3"
+
+	headerScopeOrgID = "X-Scope-OrgID"
+	pathMimirPush    = "/api/v1/push"
+)
+
+// A Backend maps tenants and backend-specific settings onto the requests
+// issued by a client, for servers that implement the Prometheus API with
+// their own extensions.
+type Backend interface {
+	// BaseURL returns the URL requests are sent to, given the URL passed
+	// to NewClient, which may be empty if the backend derives the URL
+	// from its tenant.
+	BaseURL(baseURL string) (string, error)
+
+	// Path returns the path on the backend for a Prometheus API path.
+	Path(path string) string
+
+	// Header adds backend-specific headers to a request.
+	Header(h http.Header)
+
+	// Params adds backend-specific parameters to a request for a
+	// Prometheus API path.
+	Params(path string, p url.Values)
+}
+
+// WithBackend sets the backend the client talks to. Defaults to
+// PrometheusBackend.
+func WithBackend(b Backend) ClientOpt {
+	return func(c *client) {
+		c.backend = b
+	}
+}
+
+// A hostRootedBackend serves some paths from the root of the server rather
+// than under the path of the base URL.
+type hostRootedBackend interface {
+	// hostRooted returns true if the Prometheus API path is served from
+	// the root of the server.
+	hostRooted(path string) bool
+}
+
+// PrometheusBackend returns a Backend for a plain Prometheus server, which
+// sends requests unchanged.
+func PrometheusBackend() Backend {
+	return prometheusBackend{}
+}
+
+type prometheusBackend struct{}
+
+func (prometheusBackend) BaseURL(baseURL string) (string, error) { return baseURL, nil }
+func (prometheusBackend) Path(path string) string                { return path }
+func (prometheusBackend) Header(http.Header)                     {}
+func (prometheusBackend) Params(string, url.Values)              {}
+
+// ChronosphereBackend returns a Backend for a Chronosphere tenant. If no
+// base URL is passed to NewClient, requests go to the tenant's
+// Prometheus endpoint.
+func ChronosphereBackend(tenant string) Backend {
+	return chronosphereBackend{tenant: tenant}
+}
+
+type chronosphereBackend struct {
+	prometheusBackend
+	tenant string
+}
+
+func (b chronosphereBackend) BaseURL(baseURL string) (string, error) {
+	switch {
+	case baseURL != "" && b.tenant != "":
+		return "", errors.New("only one of a base URL or a Chronosphere tenant may be set")
+	case baseURL != "":
+		return baseURL, nil
+	case b.tenant != "":
+		return fmt.Sprintf(chronosphereURL, b.tenant), nil
+	default:
+		return "", errors.New("one of a base URL or a Chronosphere tenant is required")
+	}
+}
+
+// MimirBackend returns a Backend for Grafana Mimir, which selects the
+// tenant with the X-Scope-OrgID header and accepts remote writes on
+// /api/v1/push. The base URL should include the Prometheus API prefix,
+// usually /prometheus, which the push endpoint sits outside of, so remote
+// writes go to /api/v1/push at the root of the server. An empty tenant
+// sends no header, for servers with multi-tenancy disabled.
+func MimirBackend(tenant string) Backend {
+	return mimirBackend{tenant: tenant}
+}
+
+// CortexBackend returns a Backend for Cortex, which handles tenants the
+// same way as Mimir.
+func CortexBackend(tenant string) Backend {
+	return mimirBackend{tenant: tenant}
+}
+
+type mimirBackend struct {
+	prometheusBackend
+	tenant string
+}
+
+func (b mimirBackend) Path(path string) string {
+	if path == pathRemoteWrite {
+		return pathMimirPush
+	}
+
+	return path
+}
+
+func (b mimirBackend) hostRooted(path string) bool {
+	return path == pathRemoteWrite
+}
+
+func (b mimirBackend) Header(h http.Header) {
+	if b.tenant != "" {
+		h.Set(headerScopeOrgID, b.tenant)
+	}
+}
+
+// ThanosOptions are the Thanos-specific query settings.
+type ThanosOptions struct {
+	// PartialResponse allows queries to succeed with a warning when some
+	// store APIs are unavailable, rather than failing.
+	PartialResponse bool
+
+	// DisableDedup disables deduplication of series from HA replicas.
+	DisableDedup bool
+}
+
+// ThanosBackend returns a Backend for a Thanos querier, which sends the
+// partial response and deduplication settings with every query that
+// supports them.
+func ThanosBackend(opts ThanosOptions) Backend {
+	return thanosBackend{opts: opts}
+}
+
+type thanosBackend struct {
+	prometheusBackend
+	opts ThanosOptions
+}
+
+func (b thanosBackend) Params(path string, p url.Values) {
+	switch {
+	case path == pathInstantQuery, path == pathRangeQuery, path == pathSeriesQuery:
+		p.Set("dedup", strconv.FormatBool(!b.opts.DisableDedup))
+		p.Set("partial_response", strconv.FormatBool(b.opts.PartialResponse))
+	case path == pathLabelQuery, path == pathRulesQuery, path == pathAlertsQuery,
+		strings.HasPrefix(path, "/api/v1/label/"):
+		p.Set("partial_response", strconv.FormatBool(b.opts.PartialResponse))
+	}
+}
+
+// VictoriaMetricsBackend returns a Backend for VictoriaMetrics. With a
+// tenant, requests go to the cluster version's per-tenant vmselect and
+// vminsert paths, so the base URL should point at a proxy in front of
+// both, such as vmauth. An empty tenant targets single-node
+// VictoriaMetrics.
+func VictoriaMetricsBackend(tenant string) Backend {
+	return victoriaMetricsBackend{tenant: tenant}
+}
+
+type victoriaMetricsBackend struct {
+	prometheusBackend
+	tenant string
+}
+
+func (b victoriaMetricsBackend) Path(path string) string {
+	switch {
+	case b.tenant == "":
+		return path
+	case path == pathRemoteWrite:
+		return "/insert/" + url.PathEscape(b.tenant) + "/prometheus" + path
+	default:
+		return "/select/" + url.PathEscape(b.tenant) + "/prometheus" + path
+	}
+}
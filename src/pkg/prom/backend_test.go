@@ -0,0 +1,167 @@
+package prom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordRequests returns a server that records the path, form and headers
+// of each request, answering every query with an empty success response.
+func recordRequests(t *testing.T) (*httptest.Server, *[]*http.Request) {
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests = append(requests, r)
+
+		switch r.URL.Path {
+		case "/api/v1/labels", "/select/42/prometheus/api/v1/labels":
+			_, _ = w.Write([]byte(`{"status": "success", "data": []}`))
+		case "/api/v1/alerts":
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"alerts": []}}`))
+		case "/api/v1/push", "/insert/42/prometheus/api/v1/write":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "vector", "result": []}}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestBackend_Mimir(t *testing.T) {
+	srv, requests := recordRequests(t)
+
+	c, err := NewClient(srv.URL+"/prometheus", WithBackend(MimirBackend("team-a")))
+	require.NoError(t, err)
+
+	_, err = c.InstantQuery("up").Do(context.TODO())
+	require.NoError(t, err)
+
+	require.NoError(t, c.RemoteWriter().Write(context.TODO(), model.Vector{
+		{Metric: model.Metric{"__name__": "up"}, Value: 1, Timestamp: model.Time(1000)},
+	}))
+
+	require.Len(t, *requests, 2)
+	assert.Equal(t, "/prometheus/api/v1/query", (*requests)[0].URL.Path)
+	assert.Equal(t, "team-a", (*requests)[0].Header.Get("X-Scope-OrgID"))
+	assert.Equal(t, "/api/v1/push", (*requests)[1].URL.Path)
+	assert.Equal(t, "team-a", (*requests)[1].Header.Get("X-Scope-OrgID"))
+}
+
+func TestBackend_Thanos(t *testing.T) {
+	srv, requests := recordRequests(t)
+
+	c, err := NewClient(srv.URL, WithBackend(ThanosBackend(ThanosOptions{
+		PartialResponse: true,
+	})))
+	require.NoError(t, err)
+
+	_, err = c.RangeQuery("up").
+		Start(time.Unix(0, 0)).
+		End(time.Unix(60, 0)).
+		Step(model.Duration(time.Minute)).
+		Do(context.TODO())
+	require.NoError(t, err)
+
+	_, err = c.LabelQuery().Do(context.TODO())
+	require.NoError(t, err)
+
+	_, err = c.Alerts().Do(context.TODO())
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 3)
+	assert.Equal(t, "true", (*requests)[0].Form.Get("dedup"))
+	assert.Equal(t, "true", (*requests)[0].Form.Get("partial_response"))
+	assert.Equal(t, "up", (*requests)[0].Form.Get("query"))
+
+	assert.Equal(t, url.Values{"partial_response": {"true"}}, (*requests)[1].Form)
+	assert.Equal(t, url.Values{"partial_response": {"true"}}, (*requests)[2].URL.Query())
+}
+
+func TestBackend_VictoriaMetrics(t *testing.T) {
+	srv, requests := recordRequests(t)
+
+	c, err := NewClient(srv.URL, WithBackend(VictoriaMetricsBackend("42")))
+	require.NoError(t, err)
+
+	_, err = c.LabelQuery().Do(context.TODO())
+	require.NoError(t, err)
+
+	require.NoError(t, c.RemoteWriter().Write(context.TODO(), model.Vector{
+		{Metric: model.Metric{"__name__": "up"}, Value: 1, Timestamp: model.Time(1000)},
+	}))
+
+	require.Len(t, *requests, 2)
+	assert.Equal(t, "/select/42/prometheus/api/v1/labels", (*requests)[0].URL.Path)
+	assert.Equal(t, "/insert/42/prometheus/api/v1/write", (*requests)[1].URL.Path)
+}
+
+func TestBackend_Chronosphere(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		baseURL     string
+		tenant      string
+		expected    string
+		expectedErr string
+	}{
+		{"tenant", "", "meta", "https://meta.chronosphere.io/data/m3", ""},
+		{"base url", "https://example.com/data/m3/", "", "https://example.com/data/m3/", ""},
+		{"both", "https://example.com", "meta", "", "only one of"},
+		{"neither", "", "", "", "one of a base URL or a Chronosphere tenant is required"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, err := ChronosphereBackend(tt.tenant).BaseURL(tt.baseURL)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, baseURL)
+		})
+	}
+}
+
+// tenantParamBackend is a Backend that selects the tenant with a query
+// parameter.
+type tenantParamBackend struct {
+	prometheusBackend
+}
+
+func (tenantParamBackend) Params(_ string, p url.Values) {
+	p.Set("tenant", "team-a")
+}
+
+func TestBackend_Federate(t *testing.T) {
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		_, _ = w.Write([]byte("up{job=\"api\"} 1 1000\n"))
+	}))
+	defer srv.Close()
+
+	for _, backend := range []Backend{tenantParamBackend{}, VictoriaMetricsBackend("42")} {
+		c, err := NewClient(srv.URL, WithBackend(backend))
+		require.NoError(t, err)
+
+		_, err = c.Federate(context.TODO(), `{job="api"}`)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/federate", requests[0].URL.Path)
+	assert.Equal(t, url.Values{
+		"match[]": {`{job="api"}`},
+		"tenant":  {"team-a"},
+	}, requests[0].URL.Query())
+	assert.Equal(t, "/select/42/prometheus/federate", requests[1].URL.Path)
+}
@@ -0,0 +1,87 @@
+package prom
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+// An Exemplar is a sample with additional labels (typically a trace ID)
+// linking it to the event that produced it.
+type Exemplar struct {
+	Labels    model.LabelSet    `json:"labels" yaml:"labels"`
+	Value     model.SampleValue `json:"value" yaml:"value"`
+	Timestamp model.Time        `json:"timestamp" yaml:"timestamp"`
+}
+
+// ExemplarSeries are the exemplars recorded for a single series.
+type ExemplarSeries struct {
+	SeriesLabels model.LabelSet `json:"seriesLabels" yaml:"seriesLabels"`
+	Exemplars    []Exemplar     `json:"exemplars" yaml:"exemplars"`
+}
+
+// An ExemplarQuery returns the exemplars for the series selected by a query
+// within a time range.
+type ExemplarQuery interface {
+	Start(t time.Time) ExemplarQuery
+	End(t time.Time) ExemplarQuery
+	Do(ctx context.Context) ([]ExemplarSeries, error)
+}
+
+func (c *client) ExemplarQuery(q string) ExemplarQuery {
+	return exemplarQuery{
+		c: c,
+		q: q,
+	}
+}
+
+type exemplarQuery struct {
+	c          *client
+	q          string
+	start, end time.Time
+}
+
+func (q exemplarQuery) Start(t time.Time) ExemplarQuery {
+	q.start = t
+	return q
+}
+
+func (q exemplarQuery) End(t time.Time) ExemplarQuery {
+	q.end = t
+	return q
+}
+
+func (q exemplarQuery) Do(ctx context.Context) ([]ExemplarSeries, error) {
+	p := url.Values{}
+	p.Add("query", q.q)
+
+	if !q.start.IsZero() {
+		p.Add("start", formatTime(q.start))
+	}
+
+	if !q.end.IsZero() {
+		p.Add("end", formatTime(q.end))
+	}
+
+	log := q.c.queryLog.BeginQuery("exemplar-query",
+		zap.String("query", q.q),
+		zap.Time("start", q.start),
+		zap.Time("end", q.end))
+
+	var r exemplarsResult
+	if err := q.c.post(ctx, log, pathExemplarQuery, p, &r); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if err := envelopeErr(r.Status, r.ErrorType, r.Error); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	log.QueryComplete(&r)
+	return r.Data, nil
+}
@@ -0,0 +1,145 @@
+package prom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/textparse"
+	"go.uber.org/zap"
+)
+
+const (
+	contentTypeOpenMetrics = "application/openmetrics-text"
+	acceptFederate         = "application/openmetrics-text;version=1.0.0;q=0.8,text/plain;version=0.0.4;q=0.5"
+)
+
+// openMetricsEOF terminates every OpenMetrics exposition.
+var openMetricsEOF = []byte("# EOF")
+
+// Federate scrapes the federation endpoint, returning the latest sample of
+// every series matching any of the given series selectors, e.g.
+// `{job="api"}`. At least one selector is required.
+func (c *client) Federate(ctx context.Context, selectors ...string) (model.Vector, error) {
+	if len(selectors) == 0 {
+		return nil, errors.New("at least one selector must be set for federation")
+	}
+
+	p := url.Values{}
+	for _, sel := range selectors {
+		p.Add("match[]", sel)
+	}
+
+	p = c.params(pathFederate, p)
+
+	log := c.queryLog.BeginQuery("federate",
+		zap.Strings("selectors", selectors))
+
+	var v model.Vector
+	err := c.call(ctx, log, func() error {
+		resp, err := c.doRaw(ctx, http.MethodGet, withQuery(pathFederate, p), http.Header{
+			"Accept": {acceptFederate},
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		v, err = parseExposition(b, resp.Header.Get("Content-Type"))
+		return err
+	})
+	if err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	log.QueryComplete(v)
+	return v, nil
+}
+
+// ParseExposition parses metrics in the Prometheus text or OpenMetrics
+// exposition format, as served by /metrics and /federate endpoints, into a
+// vector. OpenMetrics is detected by its trailing "# EOF". Samples without
+// a timestamp are given the current time, as a scrape would. Comments,
+// metadata and exemplars are ignored. Neither text format carries native
+// histograms, so classic histograms are returned as their bucket, sum and
+// count series.
+func ParseExposition(r io.Reader) (model.Vector, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseExposition(b, "")
+}
+
+// parseExposition parses an exposition in the format given by its content
+// type, falling back to detecting the format if the type is unknown.
+func parseExposition(b []byte, contentType string) (model.Vector, error) {
+	var p textparse.Parser
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == contentTypeOpenMetrics || bytes.HasSuffix(bytes.TrimSpace(b), openMetricsEOF) {
+		p = textparse.NewOpenMetricsParser(b)
+	} else {
+		p = textparse.NewPromParser(b)
+	}
+
+	var (
+		v   model.Vector
+		now = model.Now()
+	)
+
+	for {
+		entry, err := p.Next()
+		if errors.Is(err, io.EOF) {
+			return v, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse exposition: %w", err)
+		}
+
+		if entry != textparse.EntrySeries {
+			continue
+		}
+
+		var (
+			_, ts, value = p.Series()
+			sample       = &model.Sample{Timestamp: now, Value: model.SampleValue(value)}
+			lbls         labels.Labels
+		)
+
+		if ts != nil {
+			sample.Timestamp = model.Time(*ts)
+		}
+
+		p.Metric(&lbls)
+		sample.Metric = metricFromLabelSet(lbls)
+		v = append(v, sample)
+	}
+}
+
+// metricFromLabelSet converts a set of labels into a metric.
+func metricFromLabelSet(lbls labels.Labels) model.Metric {
+	metric := make(model.Metric, lbls.Len())
+	lbls.Range(func(l labels.Label) {
+		metric[model.LabelName(l.Name)] = model.LabelValue(l.Value)
+	})
+
+	return metric
+}
@@ -0,0 +1,118 @@
+package prom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExposition(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		text     string
+		expected model.Vector
+	}{
+		{
+			"prometheus text",
+			`# HELP http_requests_total Total HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{code="200",method="get"} 1027 1395066363000
+http_requests_total{code="400",method="post"} 3 1395066363000
+# TYPE process_open_fds gauge
+process_open_fds 12 1395066363000
+`,
+			model.Vector{
+				{
+					Metric:    model.Metric{"__name__": "http_requests_total", "code": "200", "method": "get"},
+					Value:     1027,
+					Timestamp: 1395066363000,
+				},
+				{
+					Metric:    model.Metric{"__name__": "http_requests_total", "code": "400", "method": "post"},
+					Value:     3,
+					Timestamp: 1395066363000,
+				},
+				{
+					Metric:    model.Metric{"__name__": "process_open_fds"},
+					Value:     12,
+					Timestamp: 1395066363000,
+				},
+			},
+		},
+		{
+			"openmetrics",
+			`# TYPE http_requests counter
+# HELP http_requests Total HTTP requests.
+http_requests_total{code="200"} 1027 1395066363.5 # {trace_id="KOO5S4vxi0o"} 1 1395066363.1
+# EOF
+`,
+			model.Vector{
+				{
+					Metric:    model.Metric{"__name__": "http_requests_total", "code": "200"},
+					Value:     1027,
+					Timestamp: 1395066363500,
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseExposition(strings.NewReader(tt.text))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestParseExposition_DefaultsTimestamp(t *testing.T) {
+	before := model.Now()
+	v, err := ParseExposition(strings.NewReader("up 1\n"))
+	require.NoError(t, err)
+	require.Len(t, v, 1)
+	assert.False(t, v[0].Timestamp.Before(before))
+
+	iter := NewValueIter(v)
+	require.True(t, iter.Next())
+	assert.Equal(t, "up", iter.Metric().String())
+	assert.Equal(t, float64(1), iter.FloatValue())
+}
+
+func TestParseExposition_Invalid(t *testing.T) {
+	_, err := ParseExposition(strings.NewReader("up{job=\"api\" 1\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to parse exposition")
+}
+
+func TestFederate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, pathFederate, r.URL.Path)
+		assert.Equal(t, []string{`{job="api"}`, `{__name__=~"job:.*"}`}, r.URL.Query()["match[]"])
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(`# TYPE up untyped
+up{instance="api-0:8080",job="api"} 1 1708028165516
+`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	v, err := c.Federate(context.TODO(), `{job="api"}`, `{__name__=~"job:.*"}`)
+	require.NoError(t, err)
+	assert.Equal(t, model.Vector{
+		{
+			Metric:    model.Metric{"__name__": "up", "instance": "api-0:8080", "job": "api"},
+			Value:     1,
+			Timestamp: 1708028165516,
+		},
+	}, v)
+
+	_, err = c.Federate(context.TODO())
+	assert.Error(t, err)
+}
@@ -4,11 +4,14 @@ package fakeprom
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/mmihic/golib/src/pkg/container/set"
 	"github.com/mmihic/golib/src/pkg/timex"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/mmihic/promlib/src/pkg/prom"
 )
@@ -20,6 +23,19 @@ type Client interface {
 	AddLabelQueryRules(rules ...LabelQueryRule)
 	AddSeriesQueryRules(rules ...SeriesQueryRule)
 	AddMonthlyQueryRules(rules ...MonthlyQueryRule)
+	AddPeriodQueryRules(rules ...PeriodQueryRule)
+	AddLabelValuesQueryRules(rules ...LabelValuesQueryRule)
+	AddMetadataQueryRules(rules ...MetadataQueryRule)
+	AddExemplarQueryRules(rules ...ExemplarQueryRule)
+	AddTargetsQueryRules(rules ...TargetsQueryRule)
+	AddRulesQueryRules(rules ...RulesQueryRule)
+	AddAlertsQueryRules(rules ...AlertsQueryRule)
+	AddRemoteReadRules(rules ...RemoteReadRule)
+	AddFederateRules(rules ...FederateRule)
+	SetStatus(status Status)
+
+	// Written returns the series written through RemoteWriter.
+	Written() model.Matrix
 	prom.Client
 }
 
@@ -32,20 +48,43 @@ func NewClient() Client {
 // of query rules.
 func NewClientWithRules(rules *Rules) Client {
 	return &client{
-		instants: rules.InstantQueries,
-		ranges:   rules.RangeQueries,
-		series:   rules.SeriesQueries,
-		labels:   rules.LabelQueries,
-		monthly:  rules.MonthlyQueries,
+		instants:  rules.InstantQueries,
+		ranges:    rules.RangeQueries,
+		series:    rules.SeriesQueries,
+		labels:    rules.LabelQueries,
+		monthly:   rules.MonthlyQueries,
+		periods:   rules.PeriodQueries,
+		values:    rules.LabelValuesQueries,
+		metadata:  rules.MetadataQueries,
+		exemplars: rules.ExemplarQueries,
+		targets:   rules.TargetsQueries,
+		rules:     rules.RulesQueries,
+		alerts:    rules.AlertsQueries,
+		reads:     rules.RemoteReads,
+		federate:  rules.Federate,
+		status:    rules.Status,
 	}
 }
 
 type client struct {
-	instants InstantQueryRules
-	ranges   RangeQueryRules
-	labels   LabelQueryRules
-	series   SeriesQueryRules
-	monthly  MonthlyQueryRules
+	instants  InstantQueryRules
+	ranges    RangeQueryRules
+	labels    LabelQueryRules
+	series    SeriesQueryRules
+	monthly   MonthlyQueryRules
+	periods   PeriodQueryRules
+	values    LabelValuesQueryRules
+	metadata  MetadataQueryRules
+	exemplars ExemplarQueryRules
+	targets   TargetsQueryRules
+	rules     RulesQueryRules
+	alerts    AlertsQueryRules
+	reads     RemoteReadRules
+	federate  FederateRules
+	status    Status
+
+	writtenMut sync.Mutex
+	written    model.Matrix
 }
 
 func (c *client) AddInstantQueryRules(rules ...InstantQueryRule) {
@@ -68,6 +107,57 @@ func (c *client) AddMonthlyQueryRules(rules ...MonthlyQueryRule) {
 	c.monthly = append(c.monthly, rules...)
 }
 
+func (c *client) AddPeriodQueryRules(rules ...PeriodQueryRule) {
+	c.periods = append(c.periods, rules...)
+}
+
+func (c *client) AddLabelValuesQueryRules(rules ...LabelValuesQueryRule) {
+	c.values = append(c.values, rules...)
+}
+
+func (c *client) AddMetadataQueryRules(rules ...MetadataQueryRule) {
+	c.metadata = append(c.metadata, rules...)
+}
+
+func (c *client) AddExemplarQueryRules(rules ...ExemplarQueryRule) {
+	c.exemplars = append(c.exemplars, rules...)
+}
+
+func (c *client) AddTargetsQueryRules(rules ...TargetsQueryRule) {
+	c.targets = append(c.targets, rules...)
+}
+
+func (c *client) AddRulesQueryRules(rules ...RulesQueryRule) {
+	c.rules = append(c.rules, rules...)
+}
+
+func (c *client) AddAlertsQueryRules(rules ...AlertsQueryRule) {
+	c.alerts = append(c.alerts, rules...)
+}
+
+func (c *client) AddRemoteReadRules(rules ...RemoteReadRule) {
+	c.reads = append(c.reads, rules...)
+}
+
+func (c *client) AddFederateRules(rules ...FederateRule) {
+	c.federate = append(c.federate, rules...)
+}
+
+func (c *client) SetStatus(status Status) {
+	c.status = status
+}
+
+func (c *client) Written() model.Matrix {
+	c.writtenMut.Lock()
+	defer c.writtenMut.Unlock()
+
+	return append(model.Matrix(nil), c.written...)
+}
+
+func (c *client) QueueDepth() int {
+	return 0
+}
+
 func (c *client) RangeQuery(q string) prom.RangeQuery {
 	return RangeQuery{
 		c:     c,
@@ -82,6 +172,9 @@ type RangeQuery struct {
 	StartTime  time.Time      `json:"start_time" yaml:"start_time"`
 	EndTime    time.Time      `json:"end_time" yaml:"end_time"`
 	StepPeriod model.Duration `json:"step_period" yaml:"step"`
+
+	QueryTimeout model.Duration `json:"timeout,omitempty" yaml:"timeout"`
+	WantStats    bool           `json:"-" yaml:"-"`
 }
 
 // Start sets the start time for the query.
@@ -102,12 +195,37 @@ func (q RangeQuery) Step(n model.Duration) prom.RangeQuery {
 	return q
 }
 
+// SplitBy is ignored by the fake client.
+func (q RangeQuery) SplitBy(_ time.Duration) prom.RangeQuery {
+	return q
+}
+
+// AutoSplit is ignored by the fake client.
+func (q RangeQuery) AutoSplit() prom.RangeQuery {
+	return q
+}
+
+// MaxParallel is ignored by the fake client.
+func (q RangeQuery) MaxParallel(_ int) prom.RangeQuery {
+	return q
+}
+
+// Timeout sets the server-side timeout for the query.
+func (q RangeQuery) Timeout(d time.Duration) prom.RangeQuery {
+	q.QueryTimeout = model.Duration(d)
+	return q
+}
+
 // Matches returns true if this query matches another range query.
 func (q RangeQuery) Matches(other RangeQuery) bool {
 	if q.StepPeriod != 0 && q.StepPeriod != other.StepPeriod {
 		return false
 	}
 
+	if q.QueryTimeout != 0 && q.QueryTimeout != other.QueryTimeout {
+		return false
+	}
+
 	if !q.StartTime.IsZero() && !q.StartTime.Equal(other.StartTime) {
 		return false
 	}
@@ -141,7 +259,7 @@ type MonthlyQuery struct {
 
 // Do executes the query.
 func (q MonthlyQuery) Do(_ context.Context) (*prom.Result, error) {
-	return FindMatchingResult(q.c.monthly, q)
+	return resultOrErr(FindMatchingResult(q.c.monthly, q))
 }
 
 // Start sets the start time for the query.
@@ -160,6 +278,11 @@ func (q MonthlyQuery) MaxParallel(_ int) prom.MonthlyQuery {
 	return q
 }
 
+// AtMonthEnd is ignored by the fake client.
+func (q MonthlyQuery) AtMonthEnd() prom.MonthlyQuery {
+	return q
+}
+
 // Matches checks whether this query matches another query.
 func (q MonthlyQuery) Matches(other MonthlyQuery) bool {
 	if !q.StartMonth.IsZero() && !q.StartMonth.Equal(other.StartMonth) {
@@ -178,9 +301,130 @@ func (q MonthlyQuery) Matches(other MonthlyQuery) bool {
 	return eq
 }
 
+func (c *client) PeriodQuery(q string, period prom.Period) prom.PeriodQuery {
+	return PeriodQuery{
+		c:      c,
+		Query:  q,
+		Period: period,
+	}
+}
+
+// PeriodQuery is a calendar period query.
+type PeriodQuery struct {
+	c         *client
+	Query     string      `json:"query,omitempty" yaml:"query"`
+	Period    prom.Period `json:"period,omitempty" yaml:"period"`
+	StartTime time.Time   `json:"start_time" yaml:"start_time"`
+	EndTime   time.Time   `json:"end_time" yaml:"end_time"`
+	Location  string      `json:"location,omitempty" yaml:"location"`
+}
+
+// Do executes the query.
+func (q PeriodQuery) Do(_ context.Context) (*prom.Result, error) {
+	return resultOrErr(FindMatchingResult(q.c.periods, q))
+}
+
+// Start sets the start time for the query.
+func (q PeriodQuery) Start(t time.Time) prom.PeriodQuery {
+	q.StartTime = t
+	return q
+}
+
+// End sets the end time for the query.
+func (q PeriodQuery) End(t time.Time) prom.PeriodQuery {
+	q.EndTime = t
+	return q
+}
+
+// In sets the location used to compute period boundaries.
+func (q PeriodQuery) In(loc *time.Location) prom.PeriodQuery {
+	q.Location = loc.String()
+	return q
+}
+
+// PeriodLabel is ignored by the fake client.
+func (q PeriodQuery) PeriodLabel(_ model.LabelName) prom.PeriodQuery {
+	return q
+}
+
+// MaxParallel is ignored by the fake client.
+func (q PeriodQuery) MaxParallel(_ int) prom.PeriodQuery {
+	return q
+}
+
+// AtPeriodEnd is ignored by the fake client.
+func (q PeriodQuery) AtPeriodEnd() prom.PeriodQuery {
+	return q
+}
+
+// Matches checks whether this query matches another query.
+func (q PeriodQuery) Matches(other PeriodQuery) bool {
+	if q.Period != "" && q.Period != other.Period {
+		return false
+	}
+
+	if q.Location != "" && q.Location != other.Location {
+		return false
+	}
+
+	if !q.StartTime.IsZero() && !q.StartTime.Equal(other.StartTime) {
+		return false
+	}
+
+	if !q.EndTime.IsZero() && !q.EndTime.Equal(other.EndTime) {
+		return false
+	}
+
+	eq, err := QueryEqual(q.Query, other.Query)
+	if err != nil {
+		panic(err)
+	}
+
+	return eq
+}
+
 // Do executes the range query.
 func (q RangeQuery) Do(_ context.Context) (*prom.Result, error) {
-	return FindMatchingResult(q.c.ranges, q)
+	r, err := resultOrErr(FindMatchingResult(q.c.ranges, q))
+	if err != nil {
+		return nil, err
+	}
+
+	return withoutStats(r, q.WantStats), nil
+}
+
+// WithStats returns the stats from the canned result, which are otherwise
+// stripped.
+func (q RangeQuery) WithStats() prom.RangeQuery {
+	q.WantStats = true
+	return q
+}
+
+// Stream passes each series of the canned result to fn in turn.
+func (q RangeQuery) Stream(ctx context.Context, fn prom.SeriesFunc) (*prom.Result, error) {
+	r, err := q.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch data := r.Data.(type) {
+	case model.Matrix:
+		for _, ss := range data {
+			if err := fn(prom.NewValueIter(model.Matrix{ss})); err != nil {
+				return nil, err
+			}
+		}
+	case model.Vector:
+		for _, sample := range data {
+			if err := fn(prom.NewValueIter(model.Vector{sample})); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	streamed := *r
+	streamed.Data = nil
+	return &streamed, nil
 }
 
 // InstantQuery returns a new instant query.
@@ -193,14 +437,28 @@ func (c *client) InstantQuery(q string) prom.InstantQuery {
 
 // InstantQuery is an instant query.
 type InstantQuery struct {
-	c     *client
-	Query string    `json:"query,omitempty" yaml:"query"`
-	When  time.Time `json:"when" yaml:"when"`
+	c            *client
+	Query        string         `json:"query,omitempty" yaml:"query"`
+	When         time.Time      `json:"when" yaml:"when"`
+	QueryTimeout model.Duration `json:"timeout,omitempty" yaml:"timeout"`
+	WantStats    bool           `json:"-" yaml:"-"`
 }
 
 // Do executes the instant query.
 func (q InstantQuery) Do(_ context.Context) (*prom.Result, error) {
-	return FindMatchingResult(q.c.instants, q)
+	r, err := resultOrErr(FindMatchingResult(q.c.instants, q))
+	if err != nil {
+		return nil, err
+	}
+
+	return withoutStats(r, q.WantStats), nil
+}
+
+// WithStats returns the stats from the canned result, which are otherwise
+// stripped.
+func (q InstantQuery) WithStats() prom.InstantQuery {
+	q.WantStats = true
+	return q
 }
 
 // Time sets the time for the instant query.
@@ -209,12 +467,22 @@ func (q InstantQuery) Time(t time.Time) prom.InstantQuery {
 	return q
 }
 
+// Timeout sets the server-side timeout for the instant query.
+func (q InstantQuery) Timeout(d time.Duration) prom.InstantQuery {
+	q.QueryTimeout = model.Duration(d)
+	return q
+}
+
 // Matches checks whether this query matches another query.
 func (q InstantQuery) Matches(other InstantQuery) bool {
 	if !q.When.IsZero() && !q.When.Equal(other.When) {
 		return false
 	}
 
+	if q.QueryTimeout != 0 && q.QueryTimeout != other.QueryTimeout {
+		return false
+	}
+
 	eq, err := QueryEqual(q.Query, other.Query)
 	if err != nil {
 		panic(err)
@@ -233,9 +501,10 @@ func (c *client) LabelQuery() prom.LabelQuery {
 type LabelQuery struct {
 	c *client
 
-	StartTime time.Time       `json:"start_time" yaml:"start_time"`
-	EndTime   time.Time       `json:"end_time" yaml:"end_time"`
-	Sels      set.Set[string] `json:"selectors" yaml:"selectors"`
+	StartTime  time.Time       `json:"start_time" yaml:"start_time"`
+	EndTime    time.Time       `json:"end_time" yaml:"end_time"`
+	Sels       set.Set[string] `json:"selectors" yaml:"selectors"`
+	MaxResults int             `json:"limit,omitempty" yaml:"limit"`
 }
 
 func (q LabelQuery) Start(t time.Time) prom.LabelQuery {
@@ -253,6 +522,11 @@ func (q LabelQuery) Selectors(sels []string) prom.LabelQuery {
 	return q
 }
 
+func (q LabelQuery) Limit(n int) prom.LabelQuery {
+	q.MaxResults = n
+	return q
+}
+
 func (q LabelQuery) Matches(other LabelQuery) bool {
 	if !q.StartTime.IsZero() && !q.StartTime.Equal(other.StartTime) {
 		return false
@@ -262,16 +536,25 @@ func (q LabelQuery) Matches(other LabelQuery) bool {
 		return false
 	}
 
+	if q.MaxResults != 0 && q.MaxResults != other.MaxResults {
+		return false
+	}
+
 	return q.Sels.Equal(other.Sels)
 }
 
-func (q LabelQuery) Do(_ context.Context) ([]string, error) {
+func (q LabelQuery) Do(ctx context.Context) ([]string, error) {
+	results, _, err := q.DoWithWarnings(ctx)
+	return results, err
+}
+
+func (q LabelQuery) DoWithWarnings(_ context.Context) ([]string, prom.Warnings, error) {
 	r, err := FindMatchingResult(q.c.labels, q)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return r.Labels, nil
+	return r.Labels, r.Warnings, nil
 }
 
 func (c *client) SeriesQuery() prom.SeriesQuery {
@@ -284,9 +567,10 @@ func (c *client) SeriesQuery() prom.SeriesQuery {
 type SeriesQuery struct {
 	c *client
 
-	StartTime time.Time       `json:"start_time" yaml:"start_time"`
-	EndTime   time.Time       `json:"end_time" yaml:"end_time"`
-	Sels      set.Set[string] `json:"selectors" yaml:"selectors"`
+	StartTime  time.Time       `json:"start_time" yaml:"start_time"`
+	EndTime    time.Time       `json:"end_time" yaml:"end_time"`
+	Sels       set.Set[string] `json:"selectors" yaml:"selectors"`
+	MaxResults int             `json:"limit,omitempty" yaml:"limit"`
 }
 
 func (q SeriesQuery) Start(t time.Time) prom.SeriesQuery {
@@ -304,6 +588,11 @@ func (q SeriesQuery) Selectors(sels []string) prom.SeriesQuery {
 	return q
 }
 
+func (q SeriesQuery) Limit(n int) prom.SeriesQuery {
+	q.MaxResults = n
+	return q
+}
+
 func (q SeriesQuery) Matches(other SeriesQuery) bool {
 	if !q.StartTime.IsZero() && !q.StartTime.Equal(other.StartTime) {
 		return false
@@ -313,14 +602,526 @@ func (q SeriesQuery) Matches(other SeriesQuery) bool {
 		return false
 	}
 
+	if q.MaxResults != 0 && q.MaxResults != other.MaxResults {
+		return false
+	}
+
 	return q.Sels.Equal(other.Sels)
 }
 
-func (q SeriesQuery) Do(_ context.Context) ([]model.LabelSet, error) {
+func (q SeriesQuery) Do(ctx context.Context) ([]model.LabelSet, error) {
+	results, _, err := q.DoWithWarnings(ctx)
+	return results, err
+}
+
+func (q SeriesQuery) DoWithWarnings(_ context.Context) ([]model.LabelSet, prom.Warnings, error) {
 	r, err := FindMatchingResult(q.c.series, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r.Series, r.Warnings, nil
+}
+
+func (c *client) LabelValuesQuery(label string) prom.LabelValuesQuery {
+	return LabelValuesQuery{
+		c:     c,
+		Label: label,
+	}
+}
+
+// LabelValuesQuery is a fake query for label values.
+type LabelValuesQuery struct {
+	c *client
+
+	Label     string          `json:"label" yaml:"label"`
+	StartTime time.Time       `json:"start_time" yaml:"start_time"`
+	EndTime   time.Time       `json:"end_time" yaml:"end_time"`
+	Sels      set.Set[string] `json:"selectors" yaml:"selectors"`
+	MaxValues int             `json:"limit,omitempty" yaml:"limit"`
+}
+
+func (q LabelValuesQuery) Start(t time.Time) prom.LabelValuesQuery {
+	q.StartTime = t
+	return q
+}
+
+func (q LabelValuesQuery) End(t time.Time) prom.LabelValuesQuery {
+	q.EndTime = t
+	return q
+}
+
+func (q LabelValuesQuery) Selectors(sels []string) prom.LabelValuesQuery {
+	q.Sels = set.New(sels...)
+	return q
+}
+
+func (q LabelValuesQuery) Limit(n int) prom.LabelValuesQuery {
+	q.MaxValues = n
+	return q
+}
+
+func (q LabelValuesQuery) Matches(other LabelValuesQuery) bool {
+	if q.Label != other.Label {
+		return false
+	}
+
+	if !q.StartTime.IsZero() && !q.StartTime.Equal(other.StartTime) {
+		return false
+	}
+
+	if !q.EndTime.IsZero() && !q.EndTime.Equal(other.EndTime) {
+		return false
+	}
+
+	if q.MaxValues != 0 && q.MaxValues != other.MaxValues {
+		return false
+	}
+
+	return q.Sels.Equal(other.Sels)
+}
+
+func (q LabelValuesQuery) Do(ctx context.Context) ([]string, error) {
+	values, _, err := q.DoWithWarnings(ctx)
+	return values, err
+}
+
+func (q LabelValuesQuery) DoWithWarnings(_ context.Context) ([]string, prom.Warnings, error) {
+	r, err := FindMatchingResult(q.c.values, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r.Labels, r.Warnings, nil
+}
+
+func (c *client) MetadataQuery() prom.MetadataQuery {
+	return MetadataQuery{
+		c: c,
+	}
+}
+
+// MetadataQuery is a fake query for metric metadata.
+type MetadataQuery struct {
+	c *client
+
+	MetricName   string `json:"metric,omitempty" yaml:"metric"`
+	MaxMetrics   int    `json:"limit,omitempty" yaml:"limit"`
+	MaxPerMetric int    `json:"limit_per_metric,omitempty" yaml:"limit_per_metric"`
+}
+
+func (q MetadataQuery) Metric(name string) prom.MetadataQuery {
+	q.MetricName = name
+	return q
+}
+
+func (q MetadataQuery) Limit(n int) prom.MetadataQuery {
+	q.MaxMetrics = n
+	return q
+}
+
+func (q MetadataQuery) LimitPerMetric(n int) prom.MetadataQuery {
+	q.MaxPerMetric = n
+	return q
+}
+
+func (q MetadataQuery) Matches(other MetadataQuery) bool {
+	if q.MetricName != "" && q.MetricName != other.MetricName {
+		return false
+	}
+
+	if q.MaxMetrics != 0 && q.MaxMetrics != other.MaxMetrics {
+		return false
+	}
+
+	if q.MaxPerMetric != 0 && q.MaxPerMetric != other.MaxPerMetric {
+		return false
+	}
+
+	return true
+}
+
+func (q MetadataQuery) Do(_ context.Context) (map[string][]prom.Metadata, error) {
+	r, err := FindMatchingResult(q.c.metadata, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Metadata, nil
+}
+
+func (c *client) ExemplarQuery(q string) prom.ExemplarQuery {
+	return ExemplarQuery{
+		c:     c,
+		Query: q,
+	}
+}
+
+// ExemplarQuery is a fake query for exemplars.
+type ExemplarQuery struct {
+	c         *client
+	Query     string    `json:"query,omitempty" yaml:"query"`
+	StartTime time.Time `json:"start_time" yaml:"start_time"`
+	EndTime   time.Time `json:"end_time" yaml:"end_time"`
+}
+
+// Start sets the start time for the query.
+func (q ExemplarQuery) Start(t time.Time) prom.ExemplarQuery {
+	q.StartTime = t
+	return q
+}
+
+// End sets the end time for the query.
+func (q ExemplarQuery) End(t time.Time) prom.ExemplarQuery {
+	q.EndTime = t
+	return q
+}
+
+// Matches returns true if this query matches another exemplar query.
+func (q ExemplarQuery) Matches(other ExemplarQuery) bool {
+	if !q.StartTime.IsZero() && !q.StartTime.Equal(other.StartTime) {
+		return false
+	}
+
+	if !q.EndTime.IsZero() && !q.EndTime.Equal(other.EndTime) {
+		return false
+	}
+
+	eq, err := QueryEqual(q.Query, other.Query)
+	if err != nil {
+		panic(err)
+	}
+
+	return eq
+}
+
+// Do executes the exemplar query.
+func (q ExemplarQuery) Do(_ context.Context) ([]prom.ExemplarSeries, error) {
+	r, err := FindMatchingResult(q.c.exemplars, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Exemplars, nil
+}
+
+func (c *client) Targets() prom.TargetsQuery {
+	return TargetsQuery{
+		c: c,
+	}
+}
+
+// TargetsQuery is a fake query for scrape targets.
+type TargetsQuery struct {
+	c           *client
+	TargetState prom.TargetState `json:"state,omitempty" yaml:"state"`
+	Pool        string           `json:"scrape_pool,omitempty" yaml:"scrape_pool"`
+}
+
+// State sets the target state to filter on.
+func (q TargetsQuery) State(state prom.TargetState) prom.TargetsQuery {
+	q.TargetState = state
+	return q
+}
+
+// ScrapePool sets the scrape pool to filter on.
+func (q TargetsQuery) ScrapePool(pool string) prom.TargetsQuery {
+	q.Pool = pool
+	return q
+}
+
+// Matches returns true if this query matches another targets query.
+func (q TargetsQuery) Matches(other TargetsQuery) bool {
+	if q.TargetState != "" && q.TargetState != other.TargetState {
+		return false
+	}
+
+	if q.Pool != "" && q.Pool != other.Pool {
+		return false
+	}
+
+	return true
+}
+
+// Do executes the targets query.
+func (q TargetsQuery) Do(_ context.Context) (*prom.Targets, error) {
+	r, err := FindMatchingResult(q.c.targets, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r.Targets, nil
+}
+
+func (c *client) Rules() prom.RulesQuery {
+	return RulesQuery{
+		c: c,
+	}
+}
+
+// RulesQuery is a fake query for alerting and recording rules.
+type RulesQuery struct {
+	c        *client
+	RuleType prom.RuleType `json:"type,omitempty" yaml:"type"`
+}
+
+// Type sets the rule type to filter on.
+func (q RulesQuery) Type(t prom.RuleType) prom.RulesQuery {
+	q.RuleType = t
+	return q
+}
+
+// Matches returns true if this query matches another rules query.
+func (q RulesQuery) Matches(other RulesQuery) bool {
+	return q.RuleType == "" || q.RuleType == other.RuleType
+}
+
+// Do executes the rules query.
+func (q RulesQuery) Do(_ context.Context) ([]prom.RuleGroup, error) {
+	r, err := FindMatchingResult(q.c.rules, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Groups, nil
+}
+
+func (c *client) Alerts() prom.AlertsQuery {
+	return AlertsQuery{
+		c: c,
+	}
+}
+
+// AlertsQuery is a fake query for active alerts. As with the real client,
+// the state filter is applied to the canned alerts rather than matched.
+type AlertsQuery struct {
+	c          *client
+	AlertState prom.AlertState `json:"-" yaml:"-"`
+}
+
+// State sets the alert state to filter on.
+func (q AlertsQuery) State(state prom.AlertState) prom.AlertsQuery {
+	q.AlertState = state
+	return q
+}
+
+// Matches returns true if this query matches another alerts query.
+func (q AlertsQuery) Matches(_ AlertsQuery) bool {
+	return true
+}
+
+// Do executes the alerts query.
+func (q AlertsQuery) Do(_ context.Context) ([]prom.Alert, error) {
+	r, err := FindMatchingResult(q.c.alerts, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return prom.FilterAlerts(r.Alerts, q.AlertState), nil
+}
+
+func (c *client) BuildInfo(_ context.Context) (*prom.BuildInfo, error) {
+	if c.status.BuildInfo == nil {
+		return nil, errStatusNotFound
+	}
+
+	return c.status.BuildInfo, nil
+}
+
+func (c *client) RuntimeInfo(_ context.Context) (*prom.RuntimeInfo, error) {
+	if c.status.RuntimeInfo == nil {
+		return nil, errStatusNotFound
+	}
+
+	return c.status.RuntimeInfo, nil
+}
+
+func (c *client) Flags(_ context.Context) (map[string]string, error) {
+	if c.status.Flags == nil {
+		return nil, errStatusNotFound
+	}
+
+	return c.status.Flags, nil
+}
+
+func (c *client) Config(_ context.Context) (string, error) {
+	if c.status.Config == "" {
+		return "", errStatusNotFound
+	}
+
+	return c.status.Config, nil
+}
+
+func (c *client) TSDBStatus() prom.TSDBStatusQuery {
+	return TSDBStatusQuery{
+		c: c,
+	}
+}
+
+// TSDBStatusQuery is a fake query for TSDB statistics. As with the server,
+// the limit truncates each list of statistics.
+type TSDBStatusQuery struct {
+	c        *client
+	MaxStats int
+}
+
+// Limit sets the number of entries returned in each list of statistics.
+func (q TSDBStatusQuery) Limit(n int) prom.TSDBStatusQuery {
+	q.MaxStats = n
+	return q
+}
+
+// Do executes the TSDB status query.
+func (q TSDBStatusQuery) Do(_ context.Context) (*prom.TSDBStatus, error) {
+	if q.c.status.TSDBStatus == nil {
+		return nil, errStatusNotFound
+	}
+
+	limit := q.MaxStats
+	if limit == 0 {
+		limit = 10
+	}
+
+	status := *q.c.status.TSDBStatus
+	status.SeriesCountByMetricName = truncate(status.SeriesCountByMetricName, limit)
+	status.LabelValueCountByLabelName = truncate(status.LabelValueCountByLabelName, limit)
+	status.MemoryInBytesByLabelName = truncate(status.MemoryInBytesByLabelName, limit)
+	status.SeriesCountByLabelValuePair = truncate(status.SeriesCountByLabelValuePair, limit)
+	return &status, nil
+}
+
+func truncate[T any](values []T, n int) []T {
+	if len(values) > n {
+		return values[:n]
+	}
+
+	return values
+}
+
+func (c *client) RemoteRead(matchers ...*labels.Matcher) prom.RemoteReadQuery {
+	sels := set.New[string]()
+	for _, m := range matchers {
+		sels.Add(m.String())
+	}
+
+	return RemoteReadQuery{
+		c:    c,
+		Sels: sels,
+	}
+}
+
+// RemoteReadQuery is a fake remote read query. Matchers are compared in
+// their string form, e.g. job=~"api.*".
+type RemoteReadQuery struct {
+	c         *client
+	Sels      set.Set[string] `json:"matchers" yaml:"matchers"`
+	StartTime time.Time       `json:"start" yaml:"start"`
+	EndTime   time.Time       `json:"end" yaml:"end"`
+}
+
+// Start sets the start time for the query.
+func (q RemoteReadQuery) Start(t time.Time) prom.RemoteReadQuery {
+	q.StartTime = t
+	return q
+}
+
+// End sets the end time for the query.
+func (q RemoteReadQuery) End(t time.Time) prom.RemoteReadQuery {
+	q.EndTime = t
+	return q
+}
+
+// Sampled is ignored; canned results are returned either way.
+func (q RemoteReadQuery) Sampled() prom.RemoteReadQuery {
+	return q
+}
+
+// Matches returns true if this query matches another remote read query.
+func (q RemoteReadQuery) Matches(other RemoteReadQuery) bool {
+	if !q.StartTime.IsZero() && !q.StartTime.Equal(other.StartTime) {
+		return false
+	}
+
+	if !q.EndTime.IsZero() && !q.EndTime.Equal(other.EndTime) {
+		return false
+	}
+
+	return q.Sels.Equal(other.Sels)
+}
+
+// Do executes the remote read query.
+func (q RemoteReadQuery) Do(_ context.Context) (prom.ValueIter, error) {
+	r, err := FindMatchingResult(q.c.reads, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return prom.NewValueIter(r.Series), nil
+}
+
+func (c *client) RemoteWriter() prom.RemoteWriter {
+	return RemoteWriter{
+		c: c,
+	}
+}
+
+// RemoteWriter is a fake remote writer, recording the series written so
+// they can be retrieved with Written.
+type RemoteWriter struct {
+	c *client
+}
+
+// BatchSize is ignored.
+func (w RemoteWriter) BatchSize(_ int) prom.RemoteWriter {
+	return w
+}
+
+// Shards is ignored.
+func (w RemoteWriter) Shards(_ int) prom.RemoteWriter {
+	return w
+}
+
+// Write records the series of a matrix or vector. It is safe to call
+// concurrently.
+func (w RemoteWriter) Write(_ context.Context, v model.Value) error {
+	var written model.Matrix
+	switch v := v.(type) {
+	case nil:
+	case model.Matrix:
+		written = v
+	case model.Vector:
+		for _, sample := range v {
+			written = append(written, &model.SampleStream{
+				Metric: sample.Metric,
+				Values: []model.SamplePair{{Timestamp: sample.Timestamp, Value: sample.Value}},
+			})
+		}
+	default:
+		return fmt.Errorf("unexpected value type %s", v.Type())
+	}
+
+	w.c.writtenMut.Lock()
+	defer w.c.writtenMut.Unlock()
+
+	w.c.written = append(w.c.written, written...)
+	return nil
+}
+
+// FederateQuery is a fake federation scrape.
+type FederateQuery struct {
+	Sels set.Set[string] `json:"match" yaml:"match"`
+}
+
+// Matches returns true if this scrape matches another federation scrape.
+func (q FederateQuery) Matches(other FederateQuery) bool {
+	return q.Sels.Equal(other.Sels)
+}
+
+func (c *client) Federate(_ context.Context, selectors ...string) (model.Vector, error) {
+	r, err := FindMatchingResult(c.federate, FederateQuery{Sels: set.New(selectors...)})
 	if err != nil {
 		return nil, err
 	}
 
-	return r.Series, nil
+	return r.Samples, nil
 }
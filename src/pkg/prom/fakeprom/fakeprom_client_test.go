@@ -3,12 +3,14 @@ package fakeprom
 import (
 	"context"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/mmihic/golib/src/pkg/container/set"
 	"github.com/mmihic/golib/src/pkg/timex"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -33,6 +35,7 @@ func TestFakeProm_MonthlyQuery(t *testing.T) {
 				Query:      "sum ( up )", // matches but not identical
 			},
 			&prom.Result{
+				Status: prom.StatusSuccess,
 				Data: model.Vector{
 					&model.Sample{
 						Metric: model.Metric{
@@ -54,6 +57,7 @@ func TestFakeProm_MonthlyQuery(t *testing.T) {
 				Query:      "sum ( up )", // matches but not identical
 			},
 			&prom.Result{
+				Status: prom.StatusSuccess,
 				Data: model.Vector{
 					&model.Sample{
 						Metric: model.Metric{
@@ -111,6 +115,44 @@ func TestFakeProm_MonthlyQuery(t *testing.T) {
 	}
 }
 
+func TestFakeProm_PeriodQuery(t *testing.T) {
+	c := requireTestClient(t)
+
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	r, err := c.PeriodQuery("sum ( up )", prom.PeriodWeek).
+		In(nyc).
+		Start(timex.MustParseTime(time.RFC3339, "2024-03-04T05:00:00Z")).
+		End(timex.MustParseTime(time.RFC3339, "2024-03-10T05:00:00Z")).
+		Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, &prom.Result{
+		Status: prom.StatusSuccess,
+		Data: model.Matrix{
+			&model.SampleStream{
+				Metric: model.Metric{
+					"cluster": "zed",
+					"period":  "2024-W10",
+				},
+				Values: []model.SamplePair{
+					{Timestamp: 1709528400000, Value: 2930},
+				},
+			},
+		},
+	}, r)
+
+	// Does not match a different period or location
+	_, err = c.PeriodQuery("sum ( up )", prom.PeriodMonth).
+		In(nyc).
+		Do(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+
+	_, err = c.PeriodQuery("sum ( up )", prom.PeriodWeek).
+		Do(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
 func TestFakeProm_RangeQuery(t *testing.T) {
 	c := requireTestClient(t)
 
@@ -128,6 +170,7 @@ func TestFakeProm_RangeQuery(t *testing.T) {
 				Query:     "sum ( up )", // matches but not identical
 			},
 			&prom.Result{
+				Status: prom.StatusSuccess,
 				Data: model.Vector{
 					&model.Sample{
 						Metric: model.Metric{
@@ -149,6 +192,7 @@ func TestFakeProm_RangeQuery(t *testing.T) {
 				Query:     "sum ( up )", // matches but not identical
 			},
 			&prom.Result{
+				Status: prom.StatusSuccess,
 				Data: model.Vector{
 					&model.Sample{
 						Metric: model.Metric{
@@ -207,6 +251,26 @@ func TestFakeProm_RangeQuery(t *testing.T) {
 	}
 }
 
+func TestFakeProm_RangeQuery_Stream(t *testing.T) {
+	c := requireTestClient(t)
+
+	var values []string
+	r, err := c.RangeQuery("sum(up)").
+		Start(timex.MustParseTime(time.RFC3339, "2023-04-06T00:35:15Z")).
+		End(timex.MustParseTime(time.RFC3339, "2023-04-06T00:36:15Z")).
+		Step(model.Duration(time.Minute*1)).
+		Stream(context.TODO(), func(iter prom.ValueIter) error {
+			for iter.Next() {
+				values = append(values, iter.StringValue())
+			}
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2930"}, values)
+	assert.Equal(t, prom.StatusSuccess, r.Status)
+	assert.Nil(t, r.Data)
+}
+
 func TestFakeProm_InstantQuery(t *testing.T) {
 	c := requireTestClient(t)
 
@@ -223,6 +287,7 @@ func TestFakeProm_InstantQuery(t *testing.T) {
 				Query: "sum ( up )", // matches but not identical
 			},
 			&prom.Result{
+				Status: prom.StatusSuccess,
 				Data: model.Vector{
 					&model.Sample{
 						Metric: model.Metric{
@@ -243,6 +308,7 @@ func TestFakeProm_InstantQuery(t *testing.T) {
 				Query: "sum ( up )", // matches but not identical
 			},
 			&prom.Result{
+				Status: prom.StatusSuccess,
 				Data: model.Vector{
 					&model.Sample{
 						Metric: model.Metric{
@@ -265,6 +331,39 @@ func TestFakeProm_InstantQuery(t *testing.T) {
 			nil, "this is an error",
 		},
 
+		{
+			"returns an error envelope",
+			InstantQuery{
+				When:  timex.MustParseTime(time.RFC3339, "2023-04-06T00:35:15Z"),
+				Query: "max ( up )",
+			},
+			nil, "error_type=execution, msg=query processing would load too many samples",
+		},
+
+		{
+			"returns a native histogram",
+			InstantQuery{
+				Query: "rate(http_request_duration_seconds[5m])",
+			},
+			&prom.Result{
+				Status: prom.StatusSuccess,
+				Data: model.Vector{
+					&model.Sample{
+						Metric:    model.Metric{"job": "api"},
+						Timestamp: 1708028165516,
+						Histogram: &model.SampleHistogram{
+							Count: 4,
+							Sum:   3.5,
+							Buckets: model.HistogramBuckets{
+								{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 3},
+								{Boundaries: 0, Lower: 1, Upper: 2, Count: 1},
+							},
+						},
+					},
+				},
+			}, "",
+		},
+
 		{
 			"does not match any rules",
 			InstantQuery{
@@ -353,6 +452,129 @@ func TestFakeProm_LabelQuery(t *testing.T) {
 	}
 }
 
+func TestFakeProm_LabelQuery_Limit(t *testing.T) {
+	c := requireTestClient(t)
+
+	r, warnings, err := c.LabelQuery().
+		Selectors([]string{"kube_pod_info"}).
+		Limit(2).
+		DoWithWarnings(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"namespace", "pod"}, r)
+	assert.True(t, warnings.Truncated())
+
+	_, err = c.LabelQuery().
+		Selectors([]string{"kube_pod_info"}).
+		Limit(5).
+		Do(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
+func TestFakeProm_InstantQuery_Timeout(t *testing.T) {
+	c := requireTestClient(t)
+
+	r, err := c.InstantQuery("count(up)").
+		Timeout(30 * time.Second).
+		Do(context.TODO())
+	require.NoError(t, err)
+
+	iter := r.ValueIter()
+	require.True(t, iter.Next())
+	assert.Equal(t, "42", iter.StringValue())
+
+	_, err = c.InstantQuery("count(up)").
+		Timeout(10 * time.Second).
+		Do(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
+func TestFakeProm_InstantQuery_WithStats(t *testing.T) {
+	c := requireTestClient(t)
+
+	r, err := c.InstantQuery("sum(rate(http_requests_total[5m]))").
+		WithStats().
+		Do(context.TODO())
+	require.NoError(t, err)
+	require.NotNil(t, r.Stats)
+	assert.Equal(t, int64(4800), r.Stats.Samples.TotalQueryableSamples)
+	assert.Equal(t, int64(1200), r.Stats.Samples.PeakSamples)
+	assert.Equal(t, 0.013, r.Stats.Timings.ExecTotalTime)
+
+	r, err = c.InstantQuery("sum(rate(http_requests_total[5m]))").Do(context.TODO())
+	require.NoError(t, err)
+	assert.Nil(t, r.Stats)
+}
+
+func TestFakeProm_LabelValuesQuery(t *testing.T) {
+	c := requireTestClient(t)
+
+	for _, tt := range []struct {
+		name        string
+		q           LabelValuesQuery
+		expected    []string
+		expectedErr string
+	}{
+		{
+			"matching start, end time and limit",
+			LabelValuesQuery{
+				Label:     "cluster",
+				StartTime: timex.MustParseTime(time.RFC3339, "2023-04-06T00:35:15Z"),
+				EndTime:   timex.MustParseTime(time.RFC3339, "2023-04-06T00:36:15Z"),
+				Sels:      set.New("up"),
+				MaxValues: 2,
+			},
+			[]string{"foosball", "muster"},
+			"",
+		},
+
+		{
+			"non-matching limit",
+			LabelValuesQuery{
+				Label:     "cluster",
+				StartTime: timex.MustParseTime(time.RFC3339, "2023-04-06T00:35:15Z"),
+				EndTime:   timex.MustParseTime(time.RFC3339, "2023-04-06T00:36:15Z"),
+				Sels:      set.New("up"),
+			},
+			[]string{"foosball", "muster", "zed"},
+			"",
+		},
+
+		{
+			"non-matching label",
+			LabelValuesQuery{
+				Label: "namespace",
+				Sels:  set.New("up"),
+			},
+			nil,
+			"status_code: 404, msg=matcher not found",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := c.LabelValuesQuery(tt.q.Label).
+				Start(tt.q.StartTime).
+				End(tt.q.EndTime).
+				Selectors(tt.q.Sels.All()).
+				Limit(tt.q.MaxValues).
+				Do(context.TODO())
+
+			if tt.expectedErr != "" {
+				if !assert.Error(t, err) {
+					return
+				}
+
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.expected, r)
+		})
+	}
+}
+
 func TestFakeProm_SeriesQuery(t *testing.T) {
 	c := requireTestClient(t)
 
@@ -414,6 +636,258 @@ func TestFakeProm_SeriesQuery(t *testing.T) {
 	}
 }
 
+func TestFakeProm_MetadataQuery(t *testing.T) {
+	c := requireTestClient(t)
+
+	r, err := c.MetadataQuery().
+		Metric("http_requests_total").
+		Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]prom.Metadata{
+		"http_requests_total": {
+			{Type: model.MetricTypeCounter, Help: "Total HTTP requests."},
+		},
+	}, r)
+
+	r, err = c.MetadataQuery().
+		Limit(2).
+		Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]prom.Metadata{
+		"http_requests_total": {
+			{Type: model.MetricTypeCounter, Help: "Total HTTP requests."},
+		},
+		"process_resident_memory_bytes": {
+			{Type: model.MetricTypeGauge, Help: "Resident memory size in bytes.", Unit: "bytes"},
+		},
+	}, r)
+
+	_, err = c.MetadataQuery().
+		Limit(5).
+		Do(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
+func TestFakeProm_ExemplarQuery(t *testing.T) {
+	c := requireTestClient(t)
+
+	r, err := c.ExemplarQuery("http_request_duration_seconds_bucket").
+		Start(timex.MustParseTime(time.RFC3339, "2023-04-06T00:35:15Z")).
+		End(timex.MustParseTime(time.RFC3339, "2023-04-06T00:36:15Z")).
+		Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []prom.ExemplarSeries{
+		{
+			SeriesLabels: model.LabelSet{
+				"__name__": "http_request_duration_seconds_bucket",
+				"le":       "0.5",
+			},
+			Exemplars: []prom.Exemplar{
+				{
+					Labels:    model.LabelSet{"trace_id": "EpTxMJ40fUus7aGY"},
+					Value:     0.42,
+					Timestamp: 1708028165516,
+				},
+			},
+		},
+	}, r)
+
+	_, err = c.ExemplarQuery("rpc_duration_seconds_bucket").Do(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
+func TestFakeProm_Targets(t *testing.T) {
+	c := requireTestClient(t)
+
+	r, err := c.Targets().State(prom.TargetStateActive).Do(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, r.Active, 1)
+	assert.Equal(t, prom.ActiveTarget{
+		DiscoveredLabels:   map[string]string{"__address__": "localhost:9090", "job": "prometheus"},
+		Labels:             model.LabelSet{"instance": "localhost:9090", "job": "prometheus"},
+		ScrapePool:         "prometheus",
+		ScrapeURL:          "http://localhost:9090/metrics",
+		GlobalURL:          "http://prom-0:9090/metrics",
+		LastScrape:         timex.MustParseTime(time.RFC3339Nano, "2023-04-06T00:35:15.123Z"),
+		LastScrapeDuration: 0.012,
+		Health:             prom.TargetHealthUp,
+		ScrapeInterval:     "15s",
+		ScrapeTimeout:      "10s",
+	}, r.Active[0])
+	assert.Empty(t, r.Dropped)
+
+	_, err = c.Targets().State(prom.TargetStateDropped).Do(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
+func TestFakeProm_Rules(t *testing.T) {
+	c := requireTestClient(t)
+
+	r, err := c.Rules().Type(prom.RuleTypeAlerting).Do(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, r, 1)
+	assert.Equal(t, "availability", r[0].Name)
+	require.Len(t, r[0].Rules, 1)
+
+	rule := r[0].Rules[0]
+	assert.Equal(t, "alerting", rule.Type)
+	assert.Equal(t, "InstanceDown", rule.Name)
+	assert.Equal(t, "up == 0", rule.Query)
+	assert.Equal(t, float64(300), rule.Duration)
+	assert.Equal(t, prom.AlertStateFiring, rule.State)
+	require.Len(t, rule.Alerts, 1)
+	assert.Equal(t, model.LabelValue("host-1:9100"), rule.Alerts[0].Labels["instance"])
+
+	_, err = c.Rules().Type(prom.RuleTypeRecording).Do(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
+func TestFakeProm_Alerts(t *testing.T) {
+	c := requireTestClient(t)
+
+	r, err := c.Alerts().Do(context.TODO())
+	require.NoError(t, err)
+	assert.Len(t, r, 2)
+
+	r, err = c.Alerts().State(prom.AlertStatePending).Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []prom.Alert{
+		{
+			Labels:      model.LabelSet{"alertname": "HighLatency", "service": "api"},
+			Annotations: model.LabelSet{},
+			State:       prom.AlertStatePending,
+			ActiveAt:    timex.MustParseTime(time.RFC3339, "2023-04-06T00:34:00Z"),
+			Value:       "1.2e+00",
+		},
+	}, r)
+
+	r, err = c.Alerts().State(prom.AlertStateInactive).Do(context.TODO())
+	require.NoError(t, err)
+	assert.Empty(t, r)
+}
+
+func TestFakeProm_Status(t *testing.T) {
+	c := requireTestClient(t)
+
+	buildInfo, err := c.BuildInfo(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "2.50.1", buildInfo.Version)
+	assert.Equal(t, "go1.21.7", buildInfo.GoVersion)
+
+	flags, err := c.Flags(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"storage.tsdb.retention.time": "15d"}, flags)
+
+	_, err = c.RuntimeInfo(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+
+	_, err = c.Config(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
+func TestFakeProm_TSDBStatus(t *testing.T) {
+	c := requireTestClient(t)
+
+	r, err := c.TSDBStatus().Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(508), r.HeadStats.NumSeries)
+	assert.Len(t, r.SeriesCountByMetricName, 2)
+	assert.Equal(t, []prom.TSDBStat{
+		{Name: "job=prometheus", Value: 425},
+		{Name: "instance=localhost:9090", Value: 425},
+	}, r.SeriesCountByLabelValuePair)
+
+	r, err = c.TSDBStatus().Limit(1).Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []prom.TSDBStat{
+		{Name: "net_conntrack_dialer_conn_failed_total", Value: 20},
+	}, r.SeriesCountByMetricName)
+	assert.Equal(t, []prom.TSDBStat{
+		{Name: "job=prometheus", Value: 425},
+	}, r.SeriesCountByLabelValuePair)
+}
+
+func TestFakeProm_RemoteRead(t *testing.T) {
+	c := requireTestClient(t)
+
+	iter, err := c.RemoteRead(
+		labels.MustNewMatcher(labels.MatchEqual, "job", "api"),
+		labels.MustNewMatcher(labels.MatchEqual, "__name__", "up")).
+		Start(timex.MustParseTime(time.RFC3339, "2023-04-06T00:35:00Z")).
+		End(timex.MustParseTime(time.RFC3339, "2023-04-06T00:36:00Z")).
+		Do(context.TODO())
+	require.NoError(t, err)
+
+	var values []string
+	for iter.Next() {
+		assert.Equal(t, model.LabelValue("api-0:8080"), iter.Metric()["instance"])
+		values = append(values, iter.StringValue())
+	}
+	assert.Equal(t, []string{"1", "0"}, values)
+
+	_, err = c.RemoteRead(labels.MustNewMatcher(labels.MatchEqual, "job", "api")).
+		Start(timex.MustParseTime(time.RFC3339, "2023-04-06T00:35:00Z")).
+		End(timex.MustParseTime(time.RFC3339, "2023-04-06T00:36:00Z")).
+		Do(context.TODO())
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
+func TestFakeProm_RemoteWriter(t *testing.T) {
+	c := NewClient()
+
+	err := c.RemoteWriter().Write(context.TODO(), model.Vector{
+		&model.Sample{Metric: model.Metric{"__name__": "up"}, Timestamp: 1000, Value: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, model.Matrix{
+		&model.SampleStream{
+			Metric: model.Metric{"__name__": "up"},
+			Values: []model.SamplePair{{Timestamp: 1000, Value: 1}},
+		},
+	}, c.Written())
+}
+
+func TestFakeProm_RemoteWriterConcurrent(t *testing.T) {
+	c := NewClient()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, c.RemoteWriter().Write(context.TODO(), model.Vector{
+				&model.Sample{Metric: model.Metric{"__name__": "up"}, Timestamp: model.Time(i), Value: 1},
+			}))
+			_ = c.Written()
+		}(i)
+	}
+
+	wg.Wait()
+	written := c.Written()
+	assert.Len(t, written, 8)
+
+	// The returned matrix is a copy
+	written[0] = nil
+	assert.NotNil(t, c.Written()[0])
+}
+
+func TestFakeProm_Federate(t *testing.T) {
+	c := requireTestClient(t)
+
+	v, err := c.Federate(context.TODO(), `{job="api"}`)
+	require.NoError(t, err)
+	assert.Equal(t, model.Vector{
+		&model.Sample{
+			Metric:    model.Metric{"__name__": "up", "job": "api"},
+			Timestamp: 1708028165516,
+			Value:     1,
+		},
+	}, v)
+
+	_, err = c.Federate(context.TODO(), `{job="db"}`)
+	assert.ErrorIs(t, err, prom.ErrNotFound)
+}
+
 func requireTestClient(t *testing.T) Client {
 	f, err := os.Open("testdata/test-rules.yaml")
 	require.NoError(t, err)
@@ -121,6 +121,20 @@ type Rules struct {
 	LabelQueries   LabelQueryRules   `json:"label_queries" yaml:"label_queries"`
 	SeriesQueries  SeriesQueryRules  `json:"series_queries" yaml:"series_queries"`
 	MonthlyQueries MonthlyQueryRules `json:"monthly_queries" yaml:"monthly_queries"`
+	PeriodQueries  PeriodQueryRules  `json:"period_queries" yaml:"period_queries"`
+
+	LabelValuesQueries LabelValuesQueryRules `json:"label_values_queries" yaml:"label_values_queries"`
+	MetadataQueries    MetadataQueryRules    `json:"metadata_queries" yaml:"metadata_queries"`
+	ExemplarQueries    ExemplarQueryRules    `json:"exemplar_queries" yaml:"exemplar_queries"`
+
+	TargetsQueries TargetsQueryRules `json:"targets_queries" yaml:"targets_queries"`
+	RulesQueries   RulesQueryRules   `json:"rules_queries" yaml:"rules_queries"`
+	AlertsQueries  AlertsQueryRules  `json:"alerts_queries" yaml:"alerts_queries"`
+
+	RemoteReads RemoteReadRules `json:"remote_reads" yaml:"remote_reads"`
+	Federate    FederateRules   `json:"federate" yaml:"federate"`
+
+	Status Status `json:"status" yaml:"status"`
 }
 
 // Rule type aliases.
@@ -135,18 +149,88 @@ type (
 	SeriesQueryRules  = []Rule[SeriesQuery, SeriesResults]
 	MonthlyQueryRule  = Rule[MonthlyQuery, prom.Result]
 	MonthlyQueryRules = []Rule[MonthlyQuery, prom.Result]
+	PeriodQueryRule   = Rule[PeriodQuery, prom.Result]
+	PeriodQueryRules  = []Rule[PeriodQuery, prom.Result]
+
+	LabelValuesQueryRule  = Rule[LabelValuesQuery, LabelResults]
+	LabelValuesQueryRules = []Rule[LabelValuesQuery, LabelResults]
+	MetadataQueryRule     = Rule[MetadataQuery, MetadataResults]
+	MetadataQueryRules    = []Rule[MetadataQuery, MetadataResults]
+	ExemplarQueryRule     = Rule[ExemplarQuery, ExemplarResults]
+	ExemplarQueryRules    = []Rule[ExemplarQuery, ExemplarResults]
+
+	TargetsQueryRule  = Rule[TargetsQuery, TargetsResults]
+	TargetsQueryRules = []Rule[TargetsQuery, TargetsResults]
+	RulesQueryRule    = Rule[RulesQuery, RulesResults]
+	RulesQueryRules   = []Rule[RulesQuery, RulesResults]
+	AlertsQueryRule   = Rule[AlertsQuery, AlertsResults]
+	AlertsQueryRules  = []Rule[AlertsQuery, AlertsResults]
+
+	RemoteReadRule  = Rule[RemoteReadQuery, RemoteReadResults]
+	RemoteReadRules = []Rule[RemoteReadQuery, RemoteReadResults]
+	FederateRule    = Rule[FederateQuery, FederateResults]
+	FederateRules   = []Rule[FederateQuery, FederateResults]
 )
 
-// LabelResults are the results of a labels query.
+// LabelResults are the results of a labels or label values query.
 type LabelResults struct {
-	Labels []string `json:"data" yaml:"data"`
+	Labels   []string      `json:"data" yaml:"data"`
+	Warnings prom.Warnings `json:"warnings,omitempty" yaml:"warnings"`
 }
 
 // SeriesResults are the results of a series query.
 type SeriesResults struct {
-	Series []model.LabelSet `json:"data" yaml:"data"`
+	Series   []model.LabelSet `json:"data" yaml:"data"`
+	Warnings prom.Warnings    `json:"warnings,omitempty" yaml:"warnings"`
+}
+
+// MetadataResults are the results of a metadata query.
+type MetadataResults struct {
+	Metadata map[string][]prom.Metadata `json:"data" yaml:"data"`
+}
+
+// ExemplarResults are the results of an exemplar query.
+type ExemplarResults struct {
+	Exemplars []prom.ExemplarSeries `json:"data" yaml:"data"`
+}
+
+// TargetsResults are the results of a targets query.
+type TargetsResults struct {
+	Targets prom.Targets `json:"data" yaml:"data"`
+}
+
+// RulesResults are the results of a rules query.
+type RulesResults struct {
+	Groups []prom.RuleGroup `json:"data" yaml:"data"`
+}
+
+// AlertsResults are the results of an alerts query.
+type AlertsResults struct {
+	Alerts []prom.Alert `json:"data" yaml:"data"`
+}
+
+// RemoteReadResults are the results of a remote read query.
+type RemoteReadResults struct {
+	Series model.Matrix `json:"data" yaml:"data"`
+}
+
+// FederateResults are the results of a federation scrape.
+type FederateResults struct {
+	Samples model.Vector `json:"data" yaml:"data"`
 }
 
+// Status is the canned server status returned by the status calls. Calls
+// whose status is not set fail with a not found error.
+type Status struct {
+	BuildInfo   *prom.BuildInfo   `json:"build_info,omitempty" yaml:"build_info"`
+	RuntimeInfo *prom.RuntimeInfo `json:"runtime_info,omitempty" yaml:"runtime_info"`
+	Flags       map[string]string `json:"flags,omitempty" yaml:"flags"`
+	Config      string            `json:"config,omitempty" yaml:"config"`
+	TSDBStatus  *prom.TSDBStatus  `json:"tsdb,omitempty" yaml:"tsdb"`
+}
+
+var errStatusNotFound = prom.NewErrorf(http.StatusNotFound, "status not found")
+
 // FindMatchingResult finds the result that matches a given query from a set of rules.
 func FindMatchingResult[T QueryMatcher[T], R any](rules []Rule[T, R], query T) (*R, error) {
 	for _, rule := range rules {
@@ -162,6 +246,22 @@ func FindMatchingResult[T QueryMatcher[T], R any](rules []Rule[T, R], query T) (
 	return nil, prom.NewErrorf(http.StatusNotFound, "matcher not found")
 }
 
+// resultOrErr converts a canned result whose envelope reports a failure
+// into the same Error the real client would return.
+func resultOrErr(r *prom.Result, err error) (*prom.Result, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if r != nil {
+		if err := r.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
 var (
 	_ json.Unmarshaler = &RangeQueryRule{}
 	_ yaml.Unmarshaler = &RangeQueryRule{}
@@ -169,3 +269,15 @@ var (
 	_ yaml.Unmarshaler = &InstantQueryRule{}
 	_ json.Unmarshaler = &InstantQueryRule{}
 )
+
+// withoutStats strips the stats from a canned result unless they were
+// requested, as the server would.
+func withoutStats(r *prom.Result, wantStats bool) *prom.Result {
+	if r == nil || r.Stats == nil || wantStats {
+		return r
+	}
+
+	stripped := *r
+	stripped.Stats = nil
+	return &stripped
+}
@@ -3,10 +3,9 @@ package prom
 import (
 	"context"
 	"net/url"
-	"strconv"
 	"time"
 
-	"github.com/mmihic/httplib/src/pkg/httplib"
+	"github.com/prometheus/common/model"
 	"go.uber.org/zap"
 )
 
@@ -19,6 +18,14 @@ type MetricsQuery interface {
 type InstantQuery interface {
 	MetricsQuery
 	Time(t time.Time) InstantQuery
+
+	// Timeout sets the server-side evaluation timeout. Defaults to the time
+	// remaining before the context deadline, if there is one.
+	Timeout(d time.Duration) InstantQuery
+
+	// WithStats requests query execution statistics, returned in
+	// Result.Stats.
+	WithStats() InstantQuery
 }
 
 func (c *client) InstantQuery(q string) InstantQuery {
@@ -29,9 +36,11 @@ func (c *client) InstantQuery(q string) InstantQuery {
 }
 
 type instantQuery struct {
-	c *client
-	q string
-	t time.Time
+	c       *client
+	q       string
+	t       time.Time
+	timeout time.Duration
+	stats   bool
 }
 
 func (q instantQuery) Time(t time.Time) InstantQuery {
@@ -39,27 +48,52 @@ func (q instantQuery) Time(t time.Time) InstantQuery {
 	return q
 }
 
+func (q instantQuery) Timeout(d time.Duration) InstantQuery {
+	q.timeout = d
+	return q
+}
+
+func (q instantQuery) WithStats() InstantQuery {
+	q.stats = true
+	return q
+}
+
 func (q instantQuery) Do(ctx context.Context) (*Result, error) {
 	p := url.Values{}
 	p.Add("query", q.q)
 	if !q.t.IsZero() {
-		p.Add("time", strconv.FormatInt(q.t.UTC().Unix(), 10))
+		p.Add("time", formatTime(q.t))
+	}
+
+	timeout := queryTimeout(ctx, q.timeout)
+	if timeout > 0 {
+		p.Add("timeout", model.Duration(timeout).String())
+	}
+
+	if q.stats {
+		p.Add("stats", "all")
 	}
 
 	log := q.c.queryLog.BeginQuery("instant-query",
 		zap.String("query", q.q),
-		zap.Time("time", q.t))
+		zap.Time("time", q.t),
+		zap.Duration("timeout", timeout))
 
 	var r Result
-	if err := q.c.http.Post(ctx, pathInstantQuery,
-		httplib.FormURLEncoded(p), httplib.JSON(&r)); err != nil {
+	if err := q.c.post(ctx, log, pathInstantQuery, p, &r); err != nil {
 		log.QueryFailed(err)
-		if httperr, ok := httplib.UnwrapError(err); ok {
-			return nil, NewError(httperr.StatusCode, httperr.Body.String())
-		}
 		return nil, err
 	}
 
+	if err := r.Err(); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if r.Stats != nil {
+		log.QueryStats(r.Stats)
+	}
+
 	log.QueryComplete(&r)
 	return &r, nil
 }
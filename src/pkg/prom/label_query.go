@@ -6,7 +6,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/mmihic/httplib/src/pkg/httplib"
 	"go.uber.org/zap"
 )
 
@@ -15,7 +14,15 @@ type LabelQuery interface {
 	Start(t time.Time) LabelQuery
 	End(t time.Time) LabelQuery
 	Selectors(sel []string) LabelQuery
+
+	// Limit sets the maximum number of label names to return. If the limit
+	// is hit, the server reports a truncation warning.
+	Limit(n int) LabelQuery
 	Do(ctx context.Context) ([]string, error)
+
+	// DoWithWarnings runs the query, also returning any warnings reported
+	// by the server.
+	DoWithWarnings(ctx context.Context) ([]string, Warnings, error)
 }
 
 func (c *client) LabelQuery() LabelQuery {
@@ -29,6 +36,7 @@ type labelQuery struct {
 	sels  []string
 	start time.Time
 	end   time.Time
+	limit int
 }
 
 func (q labelQuery) Selectors(sels []string) LabelQuery {
@@ -46,15 +54,25 @@ func (q labelQuery) End(t time.Time) LabelQuery {
 	return q
 }
 
+func (q labelQuery) Limit(n int) LabelQuery {
+	q.limit = n
+	return q
+}
+
 func (q labelQuery) Do(ctx context.Context) ([]string, error) {
+	labels, _, err := q.DoWithWarnings(ctx)
+	return labels, err
+}
+
+func (q labelQuery) DoWithWarnings(ctx context.Context) ([]string, Warnings, error) {
 	p := url.Values{}
 
 	if !q.start.IsZero() {
-		p.Add("start", strconv.FormatInt(q.start.Unix(), 10))
+		p.Add("start", formatTime(q.start))
 	}
 
 	if !q.end.IsZero() {
-		p.Add("end", strconv.FormatInt(q.end.Unix(), 10))
+		p.Add("end", formatTime(q.end))
 	}
 
 	if len(q.sels) != 0 {
@@ -63,24 +81,28 @@ func (q labelQuery) Do(ctx context.Context) ([]string, error) {
 		}
 	}
 
+	if q.limit != 0 {
+		p.Add("limit", strconv.Itoa(q.limit))
+	}
+
 	log := q.c.queryLog.BeginQuery("labels-query",
 		zap.Strings("sels", q.sels),
 		zap.Time("start", q.start),
-		zap.Time("end", q.end))
+		zap.Time("end", q.end),
+		zap.Int("limit", q.limit))
 
 	var r labelsResult
-	if err := q.c.http.Post(ctx, pathLabelQuery,
-		httplib.FormURLEncoded(p), httplib.JSON(&r)); err != nil {
+	if err := q.c.post(ctx, log, pathLabelQuery, p, &r); err != nil {
 		log.QueryFailed(err)
+		return nil, nil, err
+	}
 
-		if httperr, ok := httplib.UnwrapError(err); ok {
-			return nil, NewError(httperr.StatusCode, httperr.Body.String())
-		}
-
-		return nil, err
+	if err := envelopeErr(r.Status, r.ErrorType, r.Error); err != nil {
+		log.QueryFailed(err)
+		return nil, nil, err
 	}
 
 	log.QueryComplete(&r)
 
-	return r.Data, nil
+	return r.Data, r.Warnings, nil
 }
@@ -0,0 +1,112 @@
+package prom
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// A LabelValuesQuery returns the values of a label across the series that
+// match a set of selectors.
+type LabelValuesQuery interface {
+	Start(t time.Time) LabelValuesQuery
+	End(t time.Time) LabelValuesQuery
+	Selectors(sel []string) LabelValuesQuery
+	Limit(n int) LabelValuesQuery
+	Do(ctx context.Context) ([]string, error)
+
+	// DoWithWarnings runs the query, also returning any warnings reported
+	// by the server, e.g. that the values were truncated by Limit.
+	DoWithWarnings(ctx context.Context) ([]string, Warnings, error)
+}
+
+func (c *client) LabelValuesQuery(label string) LabelValuesQuery {
+	return labelValuesQuery{
+		c:     c,
+		label: label,
+	}
+}
+
+type labelValuesQuery struct {
+	c     *client
+	label string
+	sels  []string
+	start time.Time
+	end   time.Time
+	limit int
+}
+
+func (q labelValuesQuery) Selectors(sels []string) LabelValuesQuery {
+	q.sels = sels
+	return q
+}
+
+func (q labelValuesQuery) Start(t time.Time) LabelValuesQuery {
+	q.start = t
+	return q
+}
+
+func (q labelValuesQuery) End(t time.Time) LabelValuesQuery {
+	q.end = t
+	return q
+}
+
+func (q labelValuesQuery) Limit(n int) LabelValuesQuery {
+	q.limit = n
+	return q
+}
+
+func (q labelValuesQuery) Do(ctx context.Context) ([]string, error) {
+	values, _, err := q.DoWithWarnings(ctx)
+	return values, err
+}
+
+func (q labelValuesQuery) DoWithWarnings(ctx context.Context) ([]string, Warnings, error) {
+	if q.label == "" {
+		return nil, nil, fmt.Errorf("'label' must be set for label values queries")
+	}
+
+	p := url.Values{}
+
+	if !q.start.IsZero() {
+		p.Add("start", formatTime(q.start))
+	}
+
+	if !q.end.IsZero() {
+		p.Add("end", formatTime(q.end))
+	}
+
+	for _, sel := range q.sels {
+		p.Add("match[]", sel)
+	}
+
+	if q.limit != 0 {
+		p.Add("limit", strconv.Itoa(q.limit))
+	}
+
+	log := q.c.queryLog.BeginQuery("label-values-query",
+		zap.String("label", q.label),
+		zap.Strings("sels", q.sels),
+		zap.Time("start", q.start),
+		zap.Time("end", q.end),
+		zap.Int("limit", q.limit))
+
+	var r labelsResult
+	path := fmt.Sprintf(pathLabelValuesQuery, url.PathEscape(q.label))
+	if err := q.c.get(ctx, log, path, p, &r); err != nil {
+		log.QueryFailed(err)
+		return nil, nil, err
+	}
+
+	if err := envelopeErr(r.Status, r.ErrorType, r.Error); err != nil {
+		log.QueryFailed(err)
+		return nil, nil, err
+	}
+
+	log.QueryComplete(&r)
+	return r.Data, r.Warnings, nil
+}
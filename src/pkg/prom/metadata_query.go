@@ -0,0 +1,88 @@
+package prom
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+// Metadata describes a metric, as reported by the targets that expose it.
+type Metadata struct {
+	Type model.MetricType `json:"type" yaml:"type"`
+	Help string           `json:"help" yaml:"help"`
+	Unit string           `json:"unit" yaml:"unit"`
+}
+
+// A MetadataQuery returns metadata about metrics, keyed by metric name.
+type MetadataQuery interface {
+	Metric(name string) MetadataQuery
+	Limit(n int) MetadataQuery
+	LimitPerMetric(n int) MetadataQuery
+	Do(ctx context.Context) (map[string][]Metadata, error)
+}
+
+func (c *client) MetadataQuery() MetadataQuery {
+	return metadataQuery{
+		c: c,
+	}
+}
+
+type metadataQuery struct {
+	c              *client
+	metric         string
+	limit          int
+	limitPerMetric int
+}
+
+func (q metadataQuery) Metric(name string) MetadataQuery {
+	q.metric = name
+	return q
+}
+
+func (q metadataQuery) Limit(n int) MetadataQuery {
+	q.limit = n
+	return q
+}
+
+func (q metadataQuery) LimitPerMetric(n int) MetadataQuery {
+	q.limitPerMetric = n
+	return q
+}
+
+func (q metadataQuery) Do(ctx context.Context) (map[string][]Metadata, error) {
+	p := url.Values{}
+
+	if q.metric != "" {
+		p.Add("metric", q.metric)
+	}
+
+	if q.limit != 0 {
+		p.Add("limit", strconv.Itoa(q.limit))
+	}
+
+	if q.limitPerMetric != 0 {
+		p.Add("limit_per_metric", strconv.Itoa(q.limitPerMetric))
+	}
+
+	log := q.c.queryLog.BeginQuery("metadata-query",
+		zap.String("metric", q.metric),
+		zap.Int("limit", q.limit),
+		zap.Int("limit_per_metric", q.limitPerMetric))
+
+	var r metadataResult
+	if err := q.c.get(ctx, log, pathMetadataQuery, p, &r); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if err := envelopeErr(r.Status, r.ErrorType, r.Error); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	log.QueryComplete(&r)
+	return r.Data, nil
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 
 	"github.com/mmihic/golib/src/pkg/timex"
-	"github.com/prometheus/common/model"
-	"golang.org/x/sync/errgroup"
 )
 
 // A MonthlyQuery is a RangeQuery that operates on monthly data,
@@ -16,116 +14,51 @@ type MonthlyQuery interface {
 	Start(t timex.MonthYear) MonthlyQuery
 	End(t timex.MonthYear) MonthlyQuery
 	MaxParallel(n int) MonthlyQuery
+
+	// AtMonthEnd evaluates the query as an instant query at the end of each
+	// month rather than as a range query, replacing RangePlaceholder in the
+	// query with the length of the month, e.g. sum_over_time(x[$__range]).
+	AtMonthEnd() MonthlyQuery
 }
 
 func (c *client) MonthlyQuery(q string) MonthlyQuery {
+	return newMonthlyQuery(c, q)
+}
+
+// newMonthlyQuery returns a MonthlyQuery that issues its per-month queries
+// through the given client.
+func newMonthlyQuery(c Client, q string) MonthlyQuery {
 	return monthlyQuery{
-		c: c,
-		q: q,
+		pq: newPeriodQuery(c, q, PeriodMonth).PeriodLabel(""),
 	}
 }
 
+// monthlyQuery is a PeriodQuery over UTC calendar months, with samples
+// left untagged so that each series has one point per month.
 type monthlyQuery struct {
-	q           string
-	c           *client
-	start       timex.MonthYear
-	end         timex.MonthYear
-	maxParallel int
+	pq PeriodQuery
 }
 
 func (q monthlyQuery) Start(t timex.MonthYear) MonthlyQuery {
-	q.start = t
+	q.pq = q.pq.Start(t.MonthStart().DayStart())
 	return q
 }
 
 func (q monthlyQuery) End(t timex.MonthYear) MonthlyQuery {
-	q.end = t
+	q.pq = q.pq.End(t.MonthEnd().DayEnd())
 	return q
 }
 
 func (q monthlyQuery) MaxParallel(n int) MonthlyQuery {
-	q.maxParallel = n
+	q.pq = q.pq.MaxParallel(n)
 	return q
 }
 
-func (q monthlyQuery) Do(ctx context.Context) (*Result, error) {
-	var (
-		month     = q.start
-		numMonths = monthsBetween(q.start, q.end)
-		idx       int
-
-		eg errgroup.Group
-	)
-
-	maxParallel := q.maxParallel
-	if maxParallel == 0 {
-		maxParallel = numMonths
-	}
-
-	eg.SetLimit(maxParallel)
-
-	monthlyResults := make([]*Result, numMonths)
-	for !month.After(q.end) {
-		var (
-			idxForResults = idx
-			monthStart    = month.MonthStart().DayStart()
-			monthEnd      = month.MonthEnd().DayEnd()
-			daysBetween   = monthEnd.Sub(monthStart)
-		)
-
-		eg.Go(func() error {
-			rq, err := q.c.RangeQuery(q.q).
-				Start(monthStart).
-				End(monthEnd).
-				Step(model.Duration(daysBetween)).
-				Do(ctx)
-			if err != nil {
-				return err
-			}
-
-			monthlyResults[idxForResults] = rq
-			return nil
-		})
-
-		idx = idx + 1
-		month = month.NextMonth()
-	}
-
-	if err := eg.Wait(); err != nil {
-		return nil, err
-	}
-
-	var (
-		results  model.Matrix
-		warnings []string
-	)
-	for _, r := range monthlyResults {
-		results = append(results, r.Data.(model.Matrix)...)
-		if len(r.Warnings) != 0 {
-			warnings = append(warnings, r.Warnings...)
-		}
-	}
-
-	return &Result{
-		Data:     results,
-		Warnings: warnings,
-	}, nil
+func (q monthlyQuery) AtMonthEnd() MonthlyQuery {
+	q.pq = q.pq.AtPeriodEnd()
+	return q
 }
 
-func monthsBetween(start, end timex.MonthYear) int {
-	if end.Less(start) {
-		end, start = start, end
-	}
-
-	var (
-		startAsMonths = (start.Year * 12) + int(start.Month) - 1
-		fromAsMonths  = (end.Year * 12) + int(end.Month) - 1
-	)
-
-	numMonths := startAsMonths - fromAsMonths
-	if numMonths < 0 {
-		numMonths = -numMonths
-	}
-	numMonths = numMonths + 1
-	return numMonths
+func (q monthlyQuery) Do(ctx context.Context) (*Result, error) {
+	return q.pq.Do(ctx)
 }
@@ -0,0 +1,172 @@
+package prom
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmihic/golib/src/pkg/timex"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthlyQuery_MergesSeries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, pathRangeQuery, r.URL.Path)
+
+		start := r.Form.Get("start")
+		_, _ = fmt.Fprintf(w, `{
+  "status": "success",
+  "data": {
+    "resultType": "matrix",
+    "result": [
+      {"metric": {"cluster": "muster"}, "values": [[%s, "%s"]]}
+    ]
+  }
+}`, start, start)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	r, err := c.MonthlyQuery("sum(up)").
+		Start(timex.MustParseMonthYear("2024-01")).
+		End(timex.MustParseMonthYear("2024-02")).
+		Do(context.TODO())
+	require.NoError(t, err)
+
+	var (
+		jan = model.TimeFromUnix(timex.MustParseTime(time.RFC3339, "2024-01-01T00:00:00Z").Unix())
+		feb = model.TimeFromUnix(timex.MustParseTime(time.RFC3339, "2024-02-01T00:00:00Z").Unix())
+	)
+
+	assert.Equal(t, model.Matrix{
+		{
+			Metric: model.Metric{"cluster": "muster"},
+			Values: []model.SamplePair{
+				{Timestamp: jan, Value: model.SampleValue(jan.Unix())},
+				{Timestamp: feb, Value: model.SampleValue(feb.Unix())},
+			},
+		},
+	}, r.Data)
+}
+
+func TestMonthlyQuery_AtMonthEnd(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries = map[string]string{}
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, pathInstantQuery, r.URL.Path)
+
+		mu.Lock()
+		queries[r.Form.Get("time")] = r.Form.Get("query")
+		mu.Unlock()
+
+		_, _ = fmt.Fprintf(w, `{
+  "status": "success",
+  "data": {
+    "resultType": "vector",
+    "result": [
+      {"metric": {"cluster": "muster"}, "value": [%s, "1"]}
+    ]
+  }
+}`, r.Form.Get("time"))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	r, err := c.MonthlyQuery("sum_over_time(up[$__range])").
+		Start(timex.MustParseMonthYear("2024-01")).
+		End(timex.MustParseMonthYear("2024-02")).
+		AtMonthEnd().
+		Do(context.TODO())
+	require.NoError(t, err)
+
+	var (
+		endOfJan = timex.MustParseTime(time.RFC3339, "2024-02-01T00:00:00Z")
+		endOfFeb = timex.MustParseTime(time.RFC3339, "2024-03-01T00:00:00Z")
+	)
+
+	assert.Equal(t, map[string]string{
+		strconv.FormatInt(endOfJan.Unix(), 10): "sum_over_time(up[31d])",
+		strconv.FormatInt(endOfFeb.Unix(), 10): "sum_over_time(up[29d])",
+	}, queries)
+
+	assert.Equal(t, model.Matrix{
+		{
+			Metric: model.Metric{"cluster": "muster"},
+			Values: []model.SamplePair{
+				{Timestamp: model.TimeFromUnix(endOfJan.Unix()), Value: 1},
+				{Timestamp: model.TimeFromUnix(endOfFeb.Unix()), Value: 1},
+			},
+		},
+	}, r.Data)
+}
+
+func TestMonthlyQuery_UnexpectedResultType(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{
+  "status": "success",
+  "data": {"resultType": "scalar", "result": [1704067200, "1"]}
+}`)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	_, err = c.MonthlyQuery("1").
+		Start(timex.MustParseMonthYear("2024-01")).
+		End(timex.MustParseMonthYear("2024-01")).
+		AtMonthEnd().
+		Do(context.TODO())
+	assert.EqualError(t, err, "month 2024-01: unexpected result type scalar")
+}
+
+func TestMonthlyQuery_SendsOneEvaluationPerMonth(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		params []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		params = append(params, fmt.Sprintf("start=%s end=%s step=%s",
+			r.Form.Get("start"), r.Form.Get("end"), r.Form.Get("step")))
+		mu.Unlock()
+
+		_, _ = fmt.Fprint(w, `{"status": "success", "data": {"resultType": "matrix", "result": []}}`)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	_, err = c.MonthlyQuery("sum(up)").
+		Start(timex.MustParseMonthYear("2024-01")).
+		End(timex.MustParseMonthYear("2024-02")).
+		MaxParallel(1).
+		Do(context.TODO())
+	require.NoError(t, err)
+
+	// start == end, so the server evaluates the query exactly once per month
+	assert.Equal(t, []string{
+		"start=1704067200 end=1704067200 step=31d",
+		"start=1706745600 end=1706745600 step=29d",
+	}, params)
+}
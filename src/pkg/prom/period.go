@@ -0,0 +1,113 @@
+package prom
+
+import (
+	"encoding"
+	"fmt"
+	"time"
+)
+
+// A Period is a calendar period used to bucket queries.
+type Period string
+
+// Supported periods.
+const (
+	PeriodDay     Period = "day"
+	PeriodWeek    Period = "week" // ISO-8601 weeks, starting on Monday
+	PeriodMonth   Period = "month"
+	PeriodQuarter Period = "quarter"
+	PeriodYear    Period = "year"
+)
+
+// ParsePeriod parses a Period from its string form.
+func ParsePeriod(s string) (Period, error) {
+	switch p := Period(s); p {
+	case PeriodDay, PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid period '%s'", s)
+	}
+}
+
+// Validate returns an error if the period is not one of the supported
+// periods.
+func (p Period) Validate() error {
+	_, err := ParsePeriod(string(p))
+	return err
+}
+
+// UnmarshalText unmarshals the text form of a Period.
+func (p *Period) UnmarshalText(b []byte) error {
+	parsed, err := ParsePeriod(string(b))
+	if err != nil {
+		return err
+	}
+
+	*p = parsed
+	return nil
+}
+
+// Start returns the start of the period containing t, in t's location.
+// Panics if the period is not valid.
+func (p Period) Start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch p {
+	case PeriodDay:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	case PeriodWeek:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-daysSinceMonday, 0, 0, 0, 0, t.Location())
+	case PeriodMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case PeriodQuarter:
+		return time.Date(y, ((m-1)/3)*3+1, 1, 0, 0, 0, 0, t.Location())
+	case PeriodYear:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	default:
+		panic(fmt.Sprintf("unknown period '%s'", p))
+	}
+}
+
+// Next returns the start of the period following the one containing t.
+// Panics if the period is not valid.
+func (p Period) Next(t time.Time) time.Time {
+	start := p.Start(t)
+	switch p {
+	case PeriodDay:
+		return start.AddDate(0, 0, 1)
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	case PeriodQuarter:
+		return start.AddDate(0, 3, 0)
+	case PeriodYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		panic(fmt.Sprintf("unknown period '%s'", p))
+	}
+}
+
+// Name returns the name of the period containing t, e.g. 2024-01-31 for
+// days, 2024-W05 for weeks, 2024-01 for months, 2024-Q1 for quarters, and
+// 2024 for years. Panics if the period is not valid.
+func (p Period) Name(t time.Time) string {
+	switch p {
+	case PeriodDay:
+		return t.Format("2006-01-02")
+	case PeriodWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case PeriodMonth:
+		return t.Format("2006-01")
+	case PeriodQuarter:
+		return fmt.Sprintf("%04d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	case PeriodYear:
+		return t.Format("2006")
+	default:
+		panic(fmt.Sprintf("unknown period '%s'", p))
+	}
+}
+
+var (
+	_ encoding.TextUnmarshaler = (*Period)(nil)
+)
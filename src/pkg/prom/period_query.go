@@ -0,0 +1,272 @@
+package prom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// DefaultPeriodLabel is the label used to tag samples with the name of
+	// the period they came from.
+	DefaultPeriodLabel = "period"
+
+	// RangePlaceholder is replaced with the length of each period when a
+	// period query is evaluated at the end of each period, e.g.
+	// sum_over_time(x[$__range]).
+	RangePlaceholder = "$__range"
+)
+
+// A PeriodQuery is a RangeQuery that operates on calendar periods (days,
+// weeks, months, quarters or years), issuing one range query per period
+// with a step equal to the length of that period.
+type PeriodQuery interface {
+	MetricsQuery
+
+	// Start sets the start time; the query begins at the start of the
+	// period containing this time.
+	Start(t time.Time) PeriodQuery
+
+	// End sets the end time; the query ends at the end of the period
+	// containing this time.
+	End(t time.Time) PeriodQuery
+
+	// In sets the location used to compute period boundaries. Defaults
+	// to UTC, as does a nil location.
+	In(loc *time.Location) PeriodQuery
+
+	// PeriodLabel sets the label used to tag each sample with the name of
+	// its period. An empty label disables tagging.
+	PeriodLabel(name model.LabelName) PeriodQuery
+
+	// MaxParallel limits the number of per-period queries run in parallel.
+	MaxParallel(n int) PeriodQuery
+
+	// AtPeriodEnd evaluates the query as an instant query at the end of
+	// each period rather than as a range query, replacing RangePlaceholder
+	// in the query with the length of the period.
+	AtPeriodEnd() PeriodQuery
+}
+
+func (c *client) PeriodQuery(q string, period Period) PeriodQuery {
+	return newPeriodQuery(c, q, period)
+}
+
+// newPeriodQuery returns a PeriodQuery that issues its per-period queries
+// through the given client.
+func newPeriodQuery(c Client, q string, period Period) PeriodQuery {
+	return periodQuery{
+		c:           c,
+		q:           q,
+		period:      period,
+		loc:         time.UTC,
+		periodLabel: DefaultPeriodLabel,
+	}
+}
+
+type periodQuery struct {
+	c           Client
+	q           string
+	period      Period
+	start       time.Time
+	end         time.Time
+	loc         *time.Location
+	periodLabel model.LabelName
+	maxParallel int
+	atEnd       bool
+}
+
+func (q periodQuery) Start(t time.Time) PeriodQuery {
+	q.start = t
+	return q
+}
+
+func (q periodQuery) End(t time.Time) PeriodQuery {
+	q.end = t
+	return q
+}
+
+func (q periodQuery) In(loc *time.Location) PeriodQuery {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	q.loc = loc
+	return q
+}
+
+func (q periodQuery) PeriodLabel(name model.LabelName) PeriodQuery {
+	q.periodLabel = name
+	return q
+}
+
+func (q periodQuery) MaxParallel(n int) PeriodQuery {
+	q.maxParallel = n
+	return q
+}
+
+func (q periodQuery) AtPeriodEnd() PeriodQuery {
+	q.atEnd = true
+	return q
+}
+
+// periods returns the start of each period covered by the query.
+func (q periodQuery) periods() []time.Time {
+	var (
+		periods []time.Time
+		end     = q.end.In(q.loc)
+	)
+
+	for start := q.period.Start(q.start.In(q.loc)); !start.After(end); start = q.period.Next(start) {
+		periods = append(periods, start)
+	}
+
+	return periods
+}
+
+func (q periodQuery) Do(ctx context.Context) (*Result, error) {
+	if err := q.period.Validate(); err != nil {
+		return nil, err
+	}
+
+	if q.start.IsZero() {
+		return nil, fmt.Errorf("'start' must be set for %s queries", q.period)
+	}
+
+	if q.end.IsZero() {
+		return nil, fmt.Errorf("'end' must be set for %s queries", q.period)
+	}
+
+	var (
+		periods = q.periods()
+		eg      errgroup.Group
+	)
+
+	maxParallel := q.maxParallel
+	if maxParallel == 0 {
+		maxParallel = len(periods)
+	}
+
+	eg.SetLimit(maxParallel)
+
+	periodResults := make([]*Result, len(periods))
+	for idx := range periods {
+		var (
+			idxForResults = idx
+			periodStart   = periods[idx]
+		)
+
+		eg.Go(func() error {
+			r, err := q.doPeriod(ctx, periodStart)
+			if err != nil {
+				return err
+			}
+
+			periodResults[idxForResults] = r
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var (
+		matrices = make([]model.Matrix, 0, len(periodResults))
+		warnings []string
+		infos    []string
+	)
+	for idx, r := range periodResults {
+		periodName := q.period.Name(periods[idx])
+
+		m, err := asMatrix(r.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", q.period, periodName, err)
+		}
+
+		if q.periodLabel != "" {
+			m = tagMatrix(m, q.periodLabel, model.LabelValue(periodName))
+		}
+
+		matrices = append(matrices, m)
+		warnings = appendUnique(warnings, r.Warnings...)
+		infos = appendUnique(infos, r.Infos...)
+	}
+
+	return &Result{
+		Status:   StatusSuccess,
+		Data:     mergeMatrices(matrices...),
+		Warnings: warnings,
+		Infos:    infos,
+	}, nil
+}
+
+// doPeriod runs the query for the period starting at the given time.
+func (q periodQuery) doPeriod(ctx context.Context, periodStart time.Time) (*Result, error) {
+	periodEnd := q.period.Next(periodStart)
+	if q.atEnd {
+		periodLength := model.Duration(periodEnd.Sub(periodStart)).String()
+		return q.c.InstantQuery(strings.ReplaceAll(q.q, RangePlaceholder, periodLength)).
+			Time(periodEnd).
+			Do(ctx)
+	}
+
+	// Evaluate once at the start of the period: with start == end the
+	// server returns a single sample, whatever the step
+	return q.c.RangeQuery(q.q).
+		Start(periodStart).
+		End(periodStart).
+		Step(model.Duration(periodEnd.Sub(periodStart))).
+		Do(ctx)
+}
+
+// asMatrix converts a range or instant query result into a matrix.
+func asMatrix(v model.Value) (model.Matrix, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case model.Matrix:
+		return v, nil
+	case model.Vector:
+		m := make(model.Matrix, 0, len(v))
+		for _, sample := range v {
+			ss := &model.SampleStream{Metric: sample.Metric}
+			if sample.Histogram != nil {
+				ss.Histograms = []model.SampleHistogramPair{
+					{Timestamp: sample.Timestamp, Histogram: sample.Histogram},
+				}
+			} else {
+				ss.Values = []model.SamplePair{
+					{Timestamp: sample.Timestamp, Value: sample.Value},
+				}
+			}
+
+			m = append(m, ss)
+		}
+
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unexpected result type %s", v.Type())
+	}
+}
+
+// tagMatrix returns a copy of the matrix with the given label added to
+// every series.
+func tagMatrix(m model.Matrix, name model.LabelName, value model.LabelValue) model.Matrix {
+	tagged := make(model.Matrix, 0, len(m))
+	for _, ss := range m {
+		metric := ss.Metric.Clone()
+		metric[name] = value
+		tagged = append(tagged, &model.SampleStream{
+			Metric:     metric,
+			Values:     ss.Values,
+			Histograms: ss.Histograms,
+		})
+	}
+
+	return tagged
+}
@@ -0,0 +1,133 @@
+package prom
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmihic/golib/src/pkg/timex"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriod(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// A Sunday evening in New York, which is already Monday in UTC
+	when := time.Date(2024, time.March, 10, 22, 15, 0, 0, loc)
+
+	for _, tt := range []struct {
+		period        Period
+		expectedStart string
+		expectedNext  string
+		expectedName  string
+	}{
+		{PeriodDay, "2024-03-10T00:00:00-05:00", "2024-03-11T00:00:00-04:00", "2024-03-10"},
+		{PeriodWeek, "2024-03-04T00:00:00-05:00", "2024-03-11T00:00:00-04:00", "2024-W10"},
+		{PeriodMonth, "2024-03-01T00:00:00-05:00", "2024-04-01T00:00:00-04:00", "2024-03"},
+		{PeriodQuarter, "2024-01-01T00:00:00-05:00", "2024-04-01T00:00:00-04:00", "2024-Q1"},
+		{PeriodYear, "2024-01-01T00:00:00-05:00", "2025-01-01T00:00:00-05:00", "2024"},
+	} {
+		t.Run(string(tt.period), func(t *testing.T) {
+			assert.Equal(t, tt.expectedStart, tt.period.Start(when).Format(time.RFC3339))
+			assert.Equal(t, tt.expectedNext, tt.period.Next(when).Format(time.RFC3339))
+			assert.Equal(t, tt.expectedName, tt.period.Name(when))
+		})
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	p, err := ParsePeriod("quarter")
+	require.NoError(t, err)
+	assert.Equal(t, PeriodQuarter, p)
+
+	_, err = ParsePeriod("fortnight")
+	assert.EqualError(t, err, "invalid period 'fortnight'")
+}
+
+func TestPeriodQuery_Periods(t *testing.T) {
+	q := periodQuery{
+		period: PeriodWeek,
+		loc:    time.UTC,
+		start:  timex.MustParseTime(time.RFC3339, "2023-12-28T12:00:00Z"),
+		end:    timex.MustParseTime(time.RFC3339, "2024-01-15T00:00:00Z"),
+	}
+
+	var names []string
+	for _, p := range q.periods() {
+		names = append(names, q.period.Name(p))
+	}
+
+	assert.Equal(t, []string{"2023-W52", "2024-W01", "2024-W02", "2024-W03"}, names)
+}
+
+func TestPeriodQuery_InLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		params []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		params = append(params, fmt.Sprintf("start=%s step=%s", r.Form.Get("start"), r.Form.Get("step")))
+		mu.Unlock()
+
+		_, _ = fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "matrix", "result": [
+  {"metric": {"job": "api"}, "values": [[%s, "1"]]}
+]}}`, r.Form.Get("start"))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	// Clocks go forward on 2024-03-10 in New York, so that day is 23 hours
+	r, err := c.PeriodQuery("sum(up)", PeriodDay).
+		In(loc).
+		Start(time.Date(2024, time.March, 9, 12, 0, 0, 0, loc)).
+		End(time.Date(2024, time.March, 10, 12, 0, 0, 0, loc)).
+		MaxParallel(1).
+		Do(context.TODO())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"start=1709960400 step=1d",
+		"start=1710046800 step=23h",
+	}, params)
+
+	m, ok := r.Data.(model.Matrix)
+	require.True(t, ok)
+
+	var periods []string
+	for _, ss := range m {
+		periods = append(periods, string(ss.Metric[DefaultPeriodLabel]))
+	}
+
+	assert.ElementsMatch(t, []string{"2024-03-09", "2024-03-10"}, periods)
+}
+
+func TestPeriodQuery_Invalid(t *testing.T) {
+	c, err := NewClient("http://localhost:9090")
+	require.NoError(t, err)
+
+	_, err = c.PeriodQuery("sum(up)", Period("weekly")).
+		Start(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)).
+		End(time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)).
+		Do(context.TODO())
+	assert.EqualError(t, err, "invalid period 'weekly'")
+
+	// A nil location falls back to UTC rather than panicking
+	q := c.PeriodQuery("sum(up)", PeriodDay).In(nil)
+	assert.Equal(t, time.UTC, q.(periodQuery).loc)
+}
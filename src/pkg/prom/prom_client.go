@@ -2,7 +2,19 @@
 package prom
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/mmihic/httplib/src/pkg/httplib"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"go.uber.org/zap"
 
 	"github.com/mmihic/promlib/src/pkg/prom/querylog"
@@ -13,6 +25,24 @@ const (
 	pathRangeQuery   = "/api/v1/query_range"
 	pathLabelQuery   = "/api/v1/labels"
 	pathSeriesQuery  = "/api/v1/series"
+
+	pathLabelValuesQuery = "/api/v1/label/%s/values"
+	pathMetadataQuery    = "/api/v1/metadata"
+	pathExemplarQuery    = "/api/v1/query_exemplars"
+	pathTargetsQuery     = "/api/v1/targets"
+	pathRulesQuery       = "/api/v1/rules"
+	pathAlertsQuery      = "/api/v1/alerts"
+	pathRemoteRead       = "/api/v1/read"
+	pathRemoteWrite      = "/api/v1/write"
+	pathFederate         = "/federate"
+)
+
+const (
+	pathBuildInfo   = "/api/v1/status/buildinfo"
+	pathRuntimeInfo = "/api/v1/status/runtimeinfo"
+	pathFlags       = "/api/v1/status/flags"
+	pathConfig      = "/api/v1/status/config"
+	pathTSDBStatus  = "/api/v1/status/tsdb"
 )
 
 // Client is a Prometheus client for running queries.
@@ -20,14 +50,40 @@ type Client interface {
 	RangeQuery(q string) RangeQuery
 	InstantQuery(q string) InstantQuery
 	MonthlyQuery(q string) MonthlyQuery
+	PeriodQuery(q string, period Period) PeriodQuery
 	LabelQuery() LabelQuery
 	SeriesQuery() SeriesQuery
+	LabelValuesQuery(label string) LabelValuesQuery
+	MetadataQuery() MetadataQuery
+	ExemplarQuery(q string) ExemplarQuery
+	Targets() TargetsQuery
+	Rules() RulesQuery
+	Alerts() AlertsQuery
+	BuildInfo(ctx context.Context) (*BuildInfo, error)
+	RuntimeInfo(ctx context.Context) (*RuntimeInfo, error)
+	Flags(ctx context.Context) (map[string]string, error)
+	Config(ctx context.Context) (string, error)
+	TSDBStatus() TSDBStatusQuery
+	RemoteRead(matchers ...*labels.Matcher) RemoteReadQuery
+	RemoteWriter() RemoteWriter
+	Federate(ctx context.Context, selectors ...string) (model.Vector, error)
+
+	// QueueDepth returns the number of requests waiting on the client's
+	// rate or concurrency limits.
+	QueueDepth() int
 }
 
 // ClientOpt are options when creating a client.
 type ClientOpt func(*client)
 
 // WithHTTPClient sets the explicit HTTP client for talking to Prometheus.
+// Errors from httplib do not carry response headers, so Retry-After is not
+// honored for queries sent through it.
+//
+// The client is only used for JSON API queries. Streamed range queries,
+// remote read and write, and federation read raw response bodies, which
+// httplib does not expose, so always go directly through net/http. Use
+// WithHeader and WithAuth for settings that must apply to every request.
 func WithHTTPClient(httpClient httplib.Client) ClientOpt {
 	return func(c *client) {
 		c.http = httpClient
@@ -35,6 +91,9 @@ func WithHTTPClient(httpClient httplib.Client) ClientOpt {
 }
 
 // WithHTTPOptions sets options for the HTTP client used to talk to Prometheus.
+// Setting any option sends queries through httplib, with the same
+// limitations as WithHTTPClient: the options do not apply to streamed
+// range queries, remote read and write, or federation.
 func WithHTTPOptions(opt ...httplib.CallOption) ClientOpt {
 	return func(c *client) {
 		c.callOpts = append(c.callOpts, opt...)
@@ -51,13 +110,37 @@ func WithQueryLog(log *zap.Logger, logResponses bool) ClientOpt {
 // NewClient creates a new Prometheus client against a base URL and a set
 // of client options.
 func NewClient(baseURL string, opts ...ClientOpt) (Client, error) {
-	c := &client{}
+	c := &client{
+		rawHTTP: &http.Client{},
+		headers: http.Header{},
+		backend: PrometheusBackend(),
+	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
-	if c.http == nil {
+	baseURL, err := c.backend.BaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	c.baseURL = strings.TrimRight(baseURL, "/")
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	c.rootURL = (&url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host}).String()
+	c.backend.Header(c.headers)
+
+	if err := c.initTransport(); err != nil {
+		return nil, err
+	}
+
+	// Requests go directly through net/http, which exposes the response
+	// headers needed for Retry-After, unless the caller configured httplib
+	if c.http == nil && len(c.callOpts) != 0 {
 		httpc, err := httplib.NewClient(baseURL, httplib.WithDefaultCallOptions(c.callOpts...))
 		if err != nil {
 			return nil, err
@@ -74,8 +157,185 @@ func NewClient(baseURL string, opts ...ClientOpt) (Client, error) {
 	return c, nil
 }
 
+// initTransport sets up the transport for any TLS settings. TLS settings
+// cannot be applied to httplib, so cannot be combined with its options.
+func (c *client) initTransport() error {
+	if c.tlsConfig == nil && c.tlsFiles == nil {
+		return nil
+	}
+
+	if c.tlsConfig != nil && c.tlsFiles != nil {
+		return errors.New("only one of WithTLSConfig or WithTLSFiles may be set")
+	}
+
+	if c.http != nil {
+		return errors.New("WithHTTPClient cannot be combined with TLS settings, configure TLS on the client instead")
+	}
+
+	if len(c.callOpts) != 0 {
+		return errors.New("WithHTTPOptions cannot be combined with TLS settings")
+	}
+
+	if c.tlsConfig != nil {
+		c.rawHTTP.Transport = newTLSTransport(c.tlsConfig)
+		return nil
+	}
+
+	t, err := newReloadingTransport(*c.tlsFiles)
+	if err != nil {
+		return err
+	}
+
+	c.rawHTTP.Transport = t
+	return nil
+}
+
 type client struct {
-	http     httplib.Client
-	callOpts []httplib.CallOption
-	queryLog querylog.Logger
+	http        httplib.Client
+	callOpts    []httplib.CallOption
+	baseURL     string
+	rootURL     string
+	rawHTTP     *http.Client
+	headers     http.Header
+	backend     Backend
+	auth        Authenticator
+	tlsConfig   *tls.Config
+	tlsFiles    *TLSFiles
+	queryLog    querylog.Logger
+	retryPolicy RetryPolicy
+	limiter     requestLimiter
+}
+
+func (c *client) QueueDepth() int {
+	return c.limiter.queueDepth()
+}
+
+// post issues a form-encoded POST to the given path, decoding the JSON
+// response into r.
+func (c *client) post(ctx context.Context, log querylog.LoggedQuery, path string, p url.Values, r any) error {
+	p = c.params(path, p)
+	if c.http == nil {
+		return c.call(ctx, log, func() error {
+			return c.doJSON(ctx, http.MethodPost, path, http.Header{
+				"Content-Type": {"application/x-www-form-urlencoded"},
+			}, strings.NewReader(p.Encode()), r)
+		})
+	}
+
+	return c.call(ctx, log, func() error {
+		opts, err := c.requestOptions(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.http.Post(ctx, c.backend.Path(path), append(opts, httplib.FormURLEncoded(p), httplib.JSON(r))...)
+	})
+}
+
+// get issues a GET to the given path with p as query parameters, decoding
+// the JSON response into r. Used for endpoints that do not accept POST.
+func (c *client) get(ctx context.Context, log querylog.LoggedQuery, path string, p url.Values, r any) error {
+	p = c.params(path, p)
+	if c.http == nil {
+		return c.call(ctx, log, func() error {
+			return c.doJSON(ctx, http.MethodGet, withQuery(path, p), nil, nil, r)
+		})
+	}
+
+	path = withQuery(c.backend.Path(path), p)
+
+	return c.call(ctx, log, func() error {
+		opts, err := c.requestOptions(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.http.Get(ctx, path, append(opts, httplib.JSON(r))...)
+	})
+}
+
+// params returns the parameters for a request to an API path, with any
+// parameters added by the backend.
+func (c *client) params(path string, p url.Values) url.Values {
+	if p == nil {
+		p = url.Values{}
+	}
+
+	c.backend.Params(path, p)
+	return p
+}
+
+// withQuery appends the parameters to a path as a query string.
+func withQuery(path string, p url.Values) string {
+	if len(p) == 0 {
+		return path
+	}
+
+	return path + "?" + p.Encode()
+}
+
+// formatTime formats a time as fractional seconds since the epoch, which
+// every Prometheus-compatible backend accepts. Unlike RFC3339 the result
+// does not depend on t's location, and unlike a float it is exact.
+func formatTime(t time.Time) string {
+	var (
+		secs  = t.Unix()
+		nanos = t.Nanosecond()
+	)
+
+	if nanos == 0 {
+		return strconv.FormatInt(secs, 10)
+	}
+
+	sign := ""
+	if secs < 0 {
+		// Unix() rounds down, so nanos count forward from a negative second
+		sign, secs, nanos = "-", -(secs + 1), int(time.Second)-nanos
+	}
+
+	return sign + strconv.FormatInt(secs, 10) + "." + strings.TrimRight(fmt.Sprintf("%09d", nanos), "0")
+}
+
+// queryTimeout returns the timeout to send to the server for a query: the
+// explicit timeout if set, otherwise the time remaining before the context
+// deadline. The server only supports millisecond precision, so returns 0 if
+// there is less than a millisecond to go.
+func queryTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	if timeout == 0 {
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+	}
+
+	return timeout.Truncate(time.Millisecond)
+}
+
+// call makes a request subject to the client's rate limits, converting
+// HTTP failures into an Error and retrying them according to the client's
+// RetryPolicy.
+func (c *client) call(ctx context.Context, log querylog.LoggedQuery, fn func() error) error {
+	return c.callRetrying(ctx, log, IsRetryable, fn)
+}
+
+// callRetrying is call, retrying the errors for which retryable returns true.
+func (c *client) callRetrying(
+	ctx context.Context, log querylog.LoggedQuery, retryable func(error) bool, fn func() error,
+) error {
+	return c.retryPolicy.do(ctx, log, retryable, func() error {
+		release, err := c.limiter.acquire(ctx)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		if err := fn(); err != nil {
+			if httperr, ok := httplib.UnwrapError(err); ok {
+				return newHTTPError(httperr.StatusCode, httperr.Body.String())
+			}
+
+			return err
+		}
+
+		return nil
+	})
 }
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/mmihic/golib/src/pkg/timex"
 	"github.com/mmihic/httplib/src/pkg/httplib"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -68,3 +71,102 @@ func requireClient(t *testing.T) Client {
 	require.NoError(t, err)
 	return c
 }
+
+func TestFormatTime(t *testing.T) {
+	for _, tt := range []struct {
+		t        time.Time
+		expected string
+	}{
+		{time.Unix(1708028165, 0), "1708028165"},
+		{time.Unix(1708028165, 516000000), "1708028165.516"},
+		{time.Unix(1708028165, 1), "1708028165.000000001"},
+		{time.Unix(-2, 500000000), "-1.5"},
+		{time.Unix(-1, 750000000), "-0.25"},
+		{
+			timex.MustParseTime(time.RFC3339Nano, "2024-02-15T15:16:05.516-05:00"),
+			"1708028165.516",
+		},
+	} {
+		assert.Equal(t, tt.expected, formatTime(tt.t), tt.t.String())
+	}
+}
+
+func TestRangeQuery_SubSecondTimes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "1708028165.25", r.Form.Get("start"))
+		assert.Equal(t, "1708028225.25", r.Form.Get("end"))
+		assert.Equal(t, "250ms", r.Form.Get("step"))
+
+		_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "matrix", "result": []}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	start := timex.MustParseTime(time.RFC3339Nano, "2024-02-15T15:16:05.25-05:00")
+	_, err = c.RangeQuery("up").
+		Start(start).
+		End(start.Add(time.Minute)).
+		Step(model.Duration(250 * time.Millisecond)).
+		Do(context.TODO())
+	require.NoError(t, err)
+}
+
+func TestInstantQuery_Timeout(t *testing.T) {
+	var timeouts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		timeouts = append(timeouts, r.Form.Get("timeout"))
+		_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "vector", "result": []}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	// No timeout or deadline
+	_, err = c.InstantQuery("up").Do(context.TODO())
+	require.NoError(t, err)
+
+	// Explicit timeout
+	_, err = c.InstantQuery("up").Timeout(30 * time.Second).Do(context.TODO())
+	require.NoError(t, err)
+
+	// Defaults from the context deadline
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Hour)
+	defer cancel()
+
+	_, err = c.InstantQuery("up").Do(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, timeouts, 3)
+	assert.Equal(t, "", timeouts[0])
+	assert.Equal(t, "30s", timeouts[1])
+	assert.Regexp(t, `^59m5\ds`, timeouts[2])
+}
+
+func TestSeriesQuery_LimitTruncated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "1", r.Form.Get("limit"))
+		_, _ = w.Write([]byte(`{
+  "status": "success",
+  "data": [{"__name__": "up", "job": "node"}],
+  "warnings": ["results truncated due to limit"]
+}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	series, warnings, err := c.SeriesQuery().
+		Selectors([]string{"up"}).
+		Limit(1).
+		DoWithWarnings(context.TODO())
+	require.NoError(t, err)
+	assert.Len(t, series, 1)
+	assert.True(t, warnings.Truncated())
+}
@@ -1,6 +1,43 @@
 package prom
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// An ErrorType is the class of failure reported by Prometheus in the
+// errorType field of the response envelope.
+type ErrorType string
+
+// Known error types.
+const (
+	ErrorTypeNone        ErrorType = ""
+	ErrorTypeTimeout     ErrorType = "timeout"
+	ErrorTypeCanceled    ErrorType = "canceled"
+	ErrorTypeExecution   ErrorType = "execution"
+	ErrorTypeBadData     ErrorType = "bad_data"
+	ErrorTypeInternal    ErrorType = "internal"
+	ErrorTypeUnavailable ErrorType = "unavailable"
+	ErrorTypeNotFound    ErrorType = "not_found"
+)
+
+// Sentinel errors for classifying failures. An Error matches exactly one of
+// these via errors.Is, based on its ErrorType or (if Prometheus did not
+// report one) its status code.
+var (
+	ErrBadQuery     = errors.New("bad query")
+	ErrTimeout      = errors.New("query timed out")
+	ErrCanceled     = errors.New("query canceled")
+	ErrExecution    = errors.New("query execution failed")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrUnavailable  = errors.New("server unavailable")
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrInternal     = errors.New("internal server error")
+)
 
 // NewError returns a new error with a status code and message.
 func NewError(statusCode int, msg string) error {
@@ -21,10 +58,106 @@ func NewErrorf(statusCode int, msg string, args ...any) error {
 // An Error is a Prometheus error.
 type Error struct {
 	StatusCode int
+	ErrorType  ErrorType
 	Message    string
+
+	// RetryAfter is the delay requested by the server before retrying,
+	// if it sent one.
+	RetryAfter time.Duration
 }
 
 // Error returns the error message.
 func (err Error) Error() string {
+	if err.ErrorType != ErrorTypeNone {
+		return fmt.Sprintf("status_code: %d, error_type=%s, msg=%s",
+			err.StatusCode, err.ErrorType, err.Message)
+	}
+
 	return fmt.Sprintf("status_code: %d, msg=%s", err.StatusCode, err.Message)
 }
+
+// Is returns true if the target is the sentinel error for this error's class.
+func (err Error) Is(target error) bool {
+	class := err.class()
+	return class != nil && class == target
+}
+
+// Retryable returns true if the request that produced this error may
+// succeed if retried.
+func (err Error) Retryable() bool {
+	switch err.class() {
+	case ErrTimeout, ErrRateLimited, ErrUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+func (err Error) class() error {
+	switch err.ErrorType {
+	case ErrorTypeBadData:
+		return ErrBadQuery
+	case ErrorTypeTimeout:
+		return ErrTimeout
+	case ErrorTypeCanceled:
+		return ErrCanceled
+	case ErrorTypeExecution:
+		return ErrExecution
+	case ErrorTypeUnavailable:
+		return ErrUnavailable
+	case ErrorTypeNotFound:
+		return ErrNotFound
+	case ErrorTypeInternal:
+		return ErrInternal
+	}
+
+	switch err.StatusCode {
+	case http.StatusBadRequest:
+		return ErrBadQuery
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnprocessableEntity:
+		return ErrExecution
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return ErrUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrTimeout
+	case http.StatusInternalServerError:
+		return ErrInternal
+	default:
+		return nil
+	}
+}
+
+// IsRetryable returns true if err is an Error that may succeed if retried.
+func IsRetryable(err error) bool {
+	var promErr Error
+	if errors.As(err, &promErr) {
+		return promErr.Retryable()
+	}
+
+	return false
+}
+
+// newHTTPError creates an Error from a failed HTTP response, extracting the
+// errorType and error from the body if it is a Prometheus response envelope.
+func newHTTPError(statusCode int, body string) error {
+	var envelope struct {
+		ErrorType ErrorType `json:"errorType"`
+		Error     string    `json:"error"`
+	}
+
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil || envelope.Error == "" {
+		return NewError(statusCode, body)
+	}
+
+	return Error{
+		StatusCode: statusCode,
+		ErrorType:  envelope.ErrorType,
+		Message:    envelope.Error,
+	}
+}
@@ -0,0 +1,101 @@
+package prom
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError_Is(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		err       error
+		sentinel  error
+		retryable bool
+	}{
+		{
+			"bad data error type",
+			Error{StatusCode: http.StatusBadRequest, ErrorType: ErrorTypeBadData},
+			ErrBadQuery, false,
+		},
+		{
+			"timeout error type",
+			Error{StatusCode: http.StatusServiceUnavailable, ErrorType: ErrorTypeTimeout},
+			ErrTimeout, true,
+		},
+		{
+			"execution error type",
+			Error{StatusCode: http.StatusUnprocessableEntity, ErrorType: ErrorTypeExecution},
+			ErrExecution, false,
+		},
+		{
+			"rate limited status",
+			NewError(http.StatusTooManyRequests, "slow down"),
+			ErrRateLimited, true,
+		},
+		{
+			"unavailable status",
+			NewError(http.StatusServiceUnavailable, "down for maintenance"),
+			ErrUnavailable, true,
+		},
+		{
+			"not found status",
+			NewErrorf(http.StatusNotFound, "matcher not found"),
+			ErrNotFound, false,
+		},
+		{
+			"forbidden status",
+			NewError(http.StatusForbidden, "no"),
+			ErrUnauthorized, false,
+		},
+		{
+			"wrapped error",
+			fmt.Errorf("running query: %w", NewError(http.StatusGatewayTimeout, "")),
+			ErrTimeout, true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.err, tt.sentinel)
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err))
+
+			for _, other := range []error{ErrBadQuery, ErrTimeout, ErrCanceled, ErrExecution,
+				ErrRateLimited, ErrUnavailable, ErrNotFound, ErrUnauthorized, ErrInternal} {
+				if other != tt.sentinel {
+					assert.NotErrorIs(t, tt.err, other)
+				}
+			}
+		})
+	}
+}
+
+func TestIsRetryable_NotPromError(t *testing.T) {
+	assert.False(t, IsRetryable(errors.New("boom")))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestNewHTTPError(t *testing.T) {
+	err := newHTTPError(http.StatusBadRequest,
+		`{"status":"error","errorType":"bad_data","error":"1:5: parse error: unexpected end of input"}`)
+
+	var promErr Error
+	require.ErrorAs(t, err, &promErr)
+	assert.Equal(t, Error{
+		StatusCode: http.StatusBadRequest,
+		ErrorType:  ErrorTypeBadData,
+		Message:    "1:5: parse error: unexpected end of input",
+	}, promErr)
+	assert.ErrorIs(t, err, ErrBadQuery)
+
+	// Non-JSON bodies are passed through as the message
+	err = newHTTPError(http.StatusBadGateway, "<html>bad gateway</html>")
+	require.ErrorAs(t, err, &promErr)
+	assert.Equal(t, Error{
+		StatusCode: http.StatusBadGateway,
+		Message:    "<html>bad gateway</html>",
+	}, promErr)
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
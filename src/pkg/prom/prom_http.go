@@ -0,0 +1,161 @@
+package prom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmihic/promlib/src/pkg/prom/querylog"
+)
+
+// maxErrorBodySize is the most of an error response body that is read into
+// an Error.
+const maxErrorBodySize = 64 * 1024
+
+// WithHeader sets a header on every request issued by the client. Unlike
+// WithHTTPOptions, the header also applies to requests that bypass the
+// httplib client, such as streaming queries. Use WithAuth for credentials.
+func WithHeader(name, value string) ClientOpt {
+	return func(c *client) {
+		c.headers.Set(name, value)
+	}
+}
+
+// stream issues a form-encoded POST to the given path and passes the
+// response body to fn without buffering it. Only the request itself is
+// retried; once fn has been called, any failure is returned as-is.
+func (c *client) stream(
+	ctx context.Context, log querylog.LoggedQuery, path string, p url.Values, fn func(r io.Reader) error,
+) error {
+	p = c.params(path, p)
+
+	var resp *http.Response
+	err := c.call(ctx, log, func() error {
+		var err error
+		resp, err = c.doRaw(ctx, http.MethodPost, path, http.Header{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+		}, strings.NewReader(p.Encode()))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	return fn(resp.Body)
+}
+
+// doJSON issues a request directly with net/http, decoding the JSON
+// response into r.
+func (c *client) doJSON(
+	ctx context.Context, method, path string, header http.Header, body io.Reader, r any,
+) error {
+	resp, err := c.doRaw(ctx, method, path, header, body)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	return json.NewDecoder(resp.Body).Decode(r)
+}
+
+// doRaw issues a request directly with net/http, with the given headers
+// in addition to the client's, converting non-2xx responses into an Error.
+// The path is mapped onto the backend's path; any query string is kept.
+// Requests carry the client's headers, auth and TLS settings, but not
+// WithHTTPClient or WithHTTPOptions, and have no timeout beyond the
+// context's, since streamed responses may legitimately take a long time.
+func (c *client) doRaw(
+	ctx context.Context, method, path string, header http.Header, body io.Reader,
+) (*http.Response, error) {
+	path, query, hasQuery := strings.Cut(path, "?")
+	u := c.url(path)
+	if hasQuery {
+		u += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range c.headers {
+		req.Header[name] = values
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	if c.auth != nil {
+		if err := c.auth.Authenticate(ctx, req.Header); err != nil {
+			return nil, fmt.Errorf("unable to authenticate: %w", err)
+		}
+	}
+
+	resp, err := c.rawHTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	err = newHTTPError(resp.StatusCode, string(b))
+
+	var promErr Error
+	if errors.As(err, &promErr) {
+		promErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		return nil, promErr
+	}
+
+	return nil, err
+}
+
+// url returns the URL for a Prometheus API path, mapped onto the backend.
+func (c *client) url(path string) string {
+	if b, ok := c.backend.(hostRootedBackend); ok && b.hostRooted(path) {
+		return c.rootURL + c.backend.Path(path)
+	}
+
+	return c.baseURL + c.backend.Path(path)
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date. Returns 0 if the header is missing or invalid.
+func parseRetryAfter(h string) time.Duration {
+	if h == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(h); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(h); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
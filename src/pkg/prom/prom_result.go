@@ -4,15 +4,50 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"strings"
 
 	"github.com/prometheus/common/model"
 )
 
+// Status values reported in the Prometheus response envelope.
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Warnings are non-fatal warnings reported by the server alongside a result.
+type Warnings []string
+
+// Truncated returns true if the server reported that the results were
+// truncated, e.g. because they exceeded a query's limit.
+func (w Warnings) Truncated() bool {
+	for _, warning := range w {
+		if strings.Contains(warning, "truncated") {
+			return true
+		}
+	}
+
+	return false
+}
+
 // A Result is a result from a Prometheus query.
 type Result struct {
-	Data     model.Value
-	Error    string
-	Warnings []string
+	Status    string
+	Data      model.Value
+	ErrorType ErrorType
+	Error     string
+	Warnings  Warnings
+	Infos     []string
+
+	// Stats are the query statistics, if requested WithStats.
+	Stats *Stats
+}
+
+// Err returns an Error if the response envelope reports that the
+// query failed, or nil if the query succeeded.
+func (r *Result) Err() error {
+	return envelopeErr(r.Status, r.ErrorType, r.Error)
 }
 
 // ValueIter returns an iterator over the values.
@@ -48,42 +83,67 @@ func (r *Result) UnmarshalJSON(b []byte) error {
 
 // MarshalJSON marshals a result to JSON.
 func (r *Result) MarshalJSON() ([]byte, error) {
-	value, err := json.Marshal(r.Data)
-	if err != nil {
-		return nil, err
+	wire := wireResult{
+		Status:    r.Status,
+		ErrorType: r.ErrorType,
+		Error:     r.Error,
+		Warnings:  r.Warnings,
+		Infos:     r.Infos,
 	}
 
-	return json.Marshal(wireResult{
-		Data: data{
+	if r.Data != nil {
+		value, err := json.Marshal(r.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		wire.Data = &data{
 			Type:   r.Data.Type(),
 			Result: value,
-		},
-		Error:    r.Error,
-		Warnings: r.Warnings,
-	})
+			Stats:  r.Stats,
+		}
+	}
+
+	return json.Marshal(wire)
 }
 
 type wireResult struct {
-	Data     data     `json:"data"`
-	Error    string   `json:"error"`
-	Warnings []string `json:"warnings,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Data      *data     `json:"data,omitempty"`
+	ErrorType ErrorType `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Warnings  []string  `json:"warnings,omitempty"`
+	Infos     []string  `json:"infos,omitempty"`
 }
 
 func (r wireResult) ToResult() (*Result, error) {
+	result := &Result{
+		Status:    r.Status,
+		ErrorType: r.ErrorType,
+		Error:     r.Error,
+		Warnings:  r.Warnings,
+		Infos:     r.Infos,
+	}
+
+	// Failed queries do not return any data
+	if r.Data == nil || r.Data.Type == model.ValNone {
+		return result, nil
+	}
+
 	v, err := r.Data.ToValue()
 	if err != nil {
 		return nil, fmt.Errorf("unable to convert data: %w", err)
 	}
-	return &Result{
-		Data:     v,
-		Error:    r.Error,
-		Warnings: r.Warnings,
-	}, nil
+
+	result.Data = v
+	result.Stats = r.Data.Stats
+	return result, nil
 }
 
 type data struct {
 	Type   model.ValueType `json:"resultType"`
 	Result json.RawMessage `json:"result"`
+	Stats  *Stats          `json:"stats,omitempty"`
 }
 
 func (d data) ToValue() (model.Value, error) {
@@ -122,13 +182,81 @@ func (d data) ToValue() (model.Value, error) {
 }
 
 type labelsResult struct {
-	Status string   `json:"status"`
-	Data   []string `json:"data"`
+	Status    string    `json:"status"`
+	Data      []string  `json:"data"`
+	ErrorType ErrorType `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Warnings  Warnings  `json:"warnings,omitempty"`
 }
 
 type seriesResult struct {
-	Status string           `json:"status"`
-	Data   []model.LabelSet `json:"data"`
+	Status    string           `json:"status"`
+	Data      []model.LabelSet `json:"data"`
+	ErrorType ErrorType        `json:"errorType,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Warnings  Warnings         `json:"warnings,omitempty"`
+}
+
+type metadataResult struct {
+	Status    string                `json:"status"`
+	Data      map[string][]Metadata `json:"data"`
+	ErrorType ErrorType             `json:"errorType,omitempty"`
+	Error     string                `json:"error,omitempty"`
+}
+
+type exemplarsResult struct {
+	Status    string           `json:"status"`
+	Data      []ExemplarSeries `json:"data"`
+	ErrorType ErrorType        `json:"errorType,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+type targetsResult struct {
+	Status    string    `json:"status"`
+	Data      Targets   `json:"data"`
+	ErrorType ErrorType `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type rulesResult struct {
+	Status string `json:"status"`
+	Data   struct {
+		Groups []RuleGroup `json:"groups"`
+	} `json:"data"`
+	ErrorType ErrorType `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type alertsResult struct {
+	Status string `json:"status"`
+	Data   struct {
+		Alerts []Alert `json:"alerts"`
+	} `json:"data"`
+	ErrorType ErrorType `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// statusResult is the envelope for the /api/v1/status endpoints.
+type statusResult[T any] struct {
+	Status    string    `json:"status"`
+	Data      T         `json:"data"`
+	ErrorType ErrorType `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// envelopeErr converts a non-success response envelope into an Error.
+// Responses without a status (e.g. from older servers or canned
+// results) are treated as successful unless they carry an error message.
+func envelopeErr(status string, errorType ErrorType, msg string) error {
+	if status == StatusSuccess || (status == "" && msg == "") {
+		return nil
+	}
+
+	return Error{
+		StatusCode: http.StatusOK,
+		ErrorType:  errorType,
+		Message:    msg,
+	}
 }
 
 var (
@@ -32,6 +32,7 @@ func TestJSON_Vector(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, Result{
+		Status: StatusSuccess,
 		Data: model.Vector{
 			&model.Sample{
 				Metric: model.Metric{
@@ -45,3 +46,136 @@ func TestJSON_Vector(t *testing.T) {
 		},
 	}, result)
 }
+
+func TestJSON_ErrorEnvelope(t *testing.T) {
+	const asJSON = `
+{
+  "status": "error",
+  "errorType": "bad_data",
+  "error": "invalid parameter \"query\": 1:5: parse error",
+  "warnings": ["this is a warning"]
+}`
+
+	var result Result
+	err := json.Unmarshal([]byte(asJSON), &result)
+	require.NoError(t, err)
+
+	assert.Equal(t, Result{
+		Status:    StatusError,
+		ErrorType: ErrorTypeBadData,
+		Error:     `invalid parameter "query": 1:5: parse error`,
+		Warnings:  []string{"this is a warning"},
+	}, result)
+
+	var promErr Error
+	require.ErrorAs(t, result.Err(), &promErr)
+	assert.Equal(t, ErrorTypeBadData, promErr.ErrorType)
+	assert.Equal(t, `invalid parameter "query": 1:5: parse error`, promErr.Message)
+}
+
+func TestJSON_SuccessEnvelope_NoError(t *testing.T) {
+	const asJSON = `
+{
+  "status": "success",
+  "data": {"resultType": "scalar", "result": [1708028165.516, "12"]},
+  "infos": ["this is an info"]
+}`
+
+	var result Result
+	err := json.Unmarshal([]byte(asJSON), &result)
+	require.NoError(t, err)
+	assert.NoError(t, result.Err())
+	assert.Equal(t, []string{"this is an info"}, result.Infos)
+}
+
+func TestJSON_MatrixHistograms(t *testing.T) {
+	const asJSON = `
+{
+  "status": "success",
+  "data": {
+    "resultType": "matrix",
+    "result": [
+      {
+        "metric": {"__name__": "http_request_duration_seconds"},
+        "histograms": [
+          [1708028165.516, {"count": "4", "sum": "3.5", "buckets": [[0, "0.5", "1", "3"], [0, "1", "2", "1"]]}]
+        ]
+      }
+    ]
+  }
+}`
+
+	var result Result
+	err := json.Unmarshal([]byte(asJSON), &result)
+	require.NoError(t, err)
+
+	expected := &model.SampleHistogram{
+		Count: 4,
+		Sum:   3.5,
+		Buckets: model.HistogramBuckets{
+			{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 3},
+			{Boundaries: 0, Lower: 1, Upper: 2, Count: 1},
+		},
+	}
+
+	iter := result.ValueIter()
+	require.True(t, iter.Next())
+	require.True(t, iter.IsHistogram())
+	assert.Equal(t, expected, iter.HistogramValue())
+	require.False(t, iter.Next())
+
+	// Histograms survive a round trip through JSON
+	b, err := json.Marshal(&result)
+	require.NoError(t, err)
+
+	var roundTripped Result
+	require.NoError(t, json.Unmarshal(b, &roundTripped))
+	assert.Equal(t, result, roundTripped)
+}
+
+func TestJSON_Stats(t *testing.T) {
+	const asJSON = `
+{
+  "status": "success",
+  "data": {
+    "resultType": "matrix",
+    "result": [],
+    "stats": {
+      "timings": {"evalTotalTime": 0.5, "execQueueTime": 0.001, "execTotalTime": 0.6},
+      "samples": {
+        "totalQueryableSamplesPerStep": [[1708028160, 10], [1708028220.5, 12]],
+        "totalQueryableSamples": 22,
+        "peakSamples": 12
+      }
+    }
+  }
+}`
+
+	var result Result
+	err := json.Unmarshal([]byte(asJSON), &result)
+	require.NoError(t, err)
+
+	assert.Equal(t, &Stats{
+		Timings: StatsTimings{
+			EvalTotalTime: 0.5,
+			ExecQueueTime: 0.001,
+			ExecTotalTime: 0.6,
+		},
+		Samples: StatsSamples{
+			TotalQueryableSamplesPerStep: []StepStat{
+				{Timestamp: 1708028160000, Value: 10},
+				{Timestamp: 1708028220500, Value: 12},
+			},
+			TotalQueryableSamples: 22,
+			PeakSamples:           12,
+		},
+	}, result.Stats)
+
+	// Stats survive a round trip through JSON
+	b, err := json.Marshal(&result)
+	require.NoError(t, err)
+
+	var roundTripped Result
+	require.NoError(t, json.Unmarshal(b, &roundTripped))
+	assert.Equal(t, result, roundTripped)
+}
@@ -6,38 +6,84 @@ import (
 	"errors"
 	"fmt"
 	"github.com/mmihic/golib/src/pkg/cli"
-	"github.com/mmihic/httplib/src/pkg/httplib"
 	"github.com/mmihic/promlib/src/pkg/prom"
 	"go.uber.org/zap"
 )
 
 const (
-	chronoPrometheusURL = "https://%s.chronosphere.io/data/m3/"
+	defaultChronosphereTenant = "meta"
+)
+
+// Backends.
+const (
+	BackendPrometheus      = "prometheus"
+	BackendChronosphere    = "chronosphere"
+	BackendMimir           = "mimir"
+	BackendCortex          = "cortex"
+	BackendThanos          = "thanos"
+	BackendVictoriaMetrics = "victoriametrics"
+)
+
+// Authentication methods.
+const (
+	AuthAPIToken = "api-token"
+	AuthNone     = "none"
+	AuthBasic    = "basic"
+	AuthBearer   = "bearer"
+	AuthOAuth2   = "oauth2"
+	AuthHeader   = "header"
 )
 
 // ClientOptions are options for creating a client on the command line.
 type ClientOptions struct {
+	Context          string `help:"name of the context in the config file to take defaults from" env:"PROMCLI_CONTEXT"`
 	PromAPITokenFile string `name:"prom-api-token-file" help:"file containing the API token"`
 	PromServerURL    string `name:"prom-server-url" help:"Prometheus server URL"`
-	SourceTenant     string `name:"source-tenant" help:"name of the Chronosphere tenant to query" default:"meta"`
+	SourceTenant     string `name:"source-tenant" help:"tenant to query; for Chronosphere, defaults to 'meta' if no server URL is given"`
 	LogQueries       bool   `help:"set to log queries"`
 	LogResponses     bool   `help:"set to log request/response bodies"`
+
+	Auth                   string   `help:"authentication method" enum:"api-token,none,basic,bearer,oauth2,header" default:"api-token"`
+	BasicAuthUser          string   `name:"basic-auth-user" help:"username for basic auth"`
+	BasicAuthPasswordFile  string   `name:"basic-auth-password-file" help:"file containing the basic auth password, defaults to $PROM_BASIC_AUTH_PASSWORD"`
+	BearerTokenFile        string   `name:"bearer-token-file" help:"file containing the bearer token, re-read when it changes, defaults to $PROM_BEARER_TOKEN"`
+	OAuth2TokenURL         string   `name:"oauth2-token-url" help:"OAuth2 token endpoint for the client credentials flow"`
+	OAuth2ClientID         string   `name:"oauth2-client-id" help:"OAuth2 client ID"`
+	OAuth2ClientSecretFile string   `name:"oauth2-client-secret-file" help:"file containing the OAuth2 client secret, defaults to $PROM_OAUTH2_CLIENT_SECRET"`
+	OAuth2Scopes           []string `name:"oauth2-scopes" help:"OAuth2 scopes to request"`
+	AuthHeader             string   `name:"auth-header" help:"name of the header for header auth"`
+	AuthHeaderValueFile    string   `name:"auth-header-value-file" help:"file containing the auth header value, defaults to $PROM_AUTH_HEADER_VALUE"`
+
+	Backend               string `help:"type of Prometheus-compatible backend" enum:"prometheus,chronosphere,mimir,cortex,thanos,victoriametrics" default:"chronosphere"`
+	ThanosPartialResponse bool   `name:"thanos-partial-response" help:"allow Thanos queries to return partial results"`
+	ThanosNoDedup         bool   `name:"thanos-no-dedup" help:"disable Thanos deduplication of HA replicas"`
+
+	CAFile             string `name:"ca-file" help:"PEM file of CAs used to verify the server, reloaded on change"`
+	CertFile           string `name:"cert-file" help:"PEM client certificate for mutual TLS, reloaded on change"`
+	KeyFile            string `name:"key-file" help:"PEM client key for mutual TLS, reloaded on change"`
+	ServerName         string `name:"server-name" help:"name used to verify the server certificate"`
+	InsecureSkipVerify bool   `name:"insecure-skip-verify" help:"skip verification of the server certificate"`
 }
 
 // PromClient returns a prom PromClient.
 func (opts *ClientOptions) PromClient(_ context.Context) (prom.Client, error) {
-	apiToken, err := cli.ReadAPIToken(opts.PromAPITokenFile, "PROM_API_TOKEN")
+	auth, err := opts.Authenticator()
 	if err != nil {
 		return nil, err
 	}
 
-	baseURL, err := opts.getBaseURL()
+	backend, err := opts.PromBackend()
 	if err != nil {
 		return nil, err
 	}
 
-	clientOpts := []prom.ClientOpt{
-		prom.WithHTTPOptions(httplib.SetHeader("API-Token", apiToken)),
+	clientOpts := []prom.ClientOpt{prom.WithBackend(backend)}
+	if auth != nil {
+		clientOpts = append(clientOpts, prom.WithAuth(auth))
+	}
+
+	if tlsFiles := opts.TLSFiles(); tlsFiles != (prom.TLSFiles{}) {
+		clientOpts = append(clientOpts, prom.WithTLSFiles(tlsFiles))
 	}
 
 	if opts.LogQueries || opts.LogResponses {
@@ -49,22 +95,128 @@ func (opts *ClientOptions) PromClient(_ context.Context) (prom.Client, error) {
 		clientOpts = append(clientOpts, prom.WithQueryLog(log, opts.LogResponses))
 	}
 
-	return prom.NewClient(baseURL, clientOpts...)
+	return prom.NewClient(opts.PromServerURL, clientOpts...)
 }
 
-func (opts *ClientOptions) getBaseURL() (string, error) {
-	var baseURL string
-	switch {
-	case len(opts.PromServerURL) != 0 && len(opts.SourceTenant) != 0:
-		return "", errors.New("only one of --source-tenant or --prom-server-url must be specified")
-	case len(opts.PromServerURL) != 0:
-		baseURL = opts.PromServerURL
-	case len(opts.SourceTenant) != 0:
-		baseURL = fmt.Sprintf(chronoPrometheusURL, opts.SourceTenant)
+// PromBackend returns the Backend for the selected backend type. Every
+// backend except Chronosphere requires --prom-server-url.
+func (opts *ClientOptions) PromBackend() (prom.Backend, error) {
+	if opts.Backend == BackendChronosphere || opts.Backend == "" {
+		tenant := opts.SourceTenant
+		switch {
+		case len(opts.PromServerURL) != 0 && len(tenant) != 0:
+			return nil, errors.New("only one of --source-tenant or --prom-server-url must be specified")
+		case len(opts.PromServerURL) == 0 && len(tenant) == 0:
+			tenant = defaultChronosphereTenant
+		}
+
+		return prom.ChronosphereBackend(tenant), nil
+	}
+
+	if len(opts.PromServerURL) == 0 {
+		return nil, fmt.Errorf("--prom-server-url is required for the %s backend", opts.Backend)
+	}
+
+	switch opts.Backend {
+	case BackendPrometheus:
+		return prom.PrometheusBackend(), nil
+	case BackendMimir:
+		return prom.MimirBackend(opts.SourceTenant), nil
+	case BackendCortex:
+		return prom.CortexBackend(opts.SourceTenant), nil
+	case BackendThanos:
+		return prom.ThanosBackend(prom.ThanosOptions{
+			PartialResponse: opts.ThanosPartialResponse,
+			DisableDedup:    opts.ThanosNoDedup,
+		}), nil
+	case BackendVictoriaMetrics:
+		return prom.VictoriaMetricsBackend(opts.SourceTenant), nil
+	default:
+		return nil, fmt.Errorf("unknown backend '%s'", opts.Backend)
+	}
+}
+
+// Authenticator returns the Authenticator for the selected authentication
+// method, or nil if authentication is disabled. Secrets are read from
+// files if given, otherwise from environment variables.
+func (opts *ClientOptions) Authenticator() (prom.Authenticator, error) {
+	switch opts.Auth {
+	case AuthAPIToken, "":
+		apiToken, err := cli.ReadAPIToken(opts.PromAPITokenFile, "PROM_API_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+
+		return prom.HeaderAuth("API-Token", apiToken), nil
+
+	case AuthNone:
+		return nil, nil
+
+	case AuthBasic:
+		if opts.BasicAuthUser == "" {
+			return nil, errors.New("--basic-auth-user is required for basic auth")
+		}
+
+		password, err := cli.ReadAPIToken(opts.BasicAuthPasswordFile, "PROM_BASIC_AUTH_PASSWORD")
+		if err != nil {
+			return nil, err
+		}
+
+		return prom.BasicAuth(opts.BasicAuthUser, password), nil
+
+	case AuthBearer:
+		if opts.BearerTokenFile != "" {
+			return prom.BearerTokenFile(opts.BearerTokenFile), nil
+		}
+
+		token, err := cli.ReadAPIToken("", "PROM_BEARER_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+
+		return prom.BearerToken(token), nil
+
+	case AuthOAuth2:
+		if opts.OAuth2TokenURL == "" || opts.OAuth2ClientID == "" {
+			return nil, errors.New("--oauth2-token-url and --oauth2-client-id are required for oauth2 auth")
+		}
+
+		secret, err := cli.ReadAPIToken(opts.OAuth2ClientSecretFile, "PROM_OAUTH2_CLIENT_SECRET")
+		if err != nil {
+			return nil, err
+		}
+
+		return prom.OAuth2ClientCredentials(prom.OAuth2Config{
+			TokenURL:     opts.OAuth2TokenURL,
+			ClientID:     opts.OAuth2ClientID,
+			ClientSecret: secret,
+			Scopes:       opts.OAuth2Scopes,
+		}), nil
+
+	case AuthHeader:
+		if opts.AuthHeader == "" {
+			return nil, errors.New("--auth-header is required for header auth")
+		}
+
+		value, err := cli.ReadAPIToken(opts.AuthHeaderValueFile, "PROM_AUTH_HEADER_VALUE")
+		if err != nil {
+			return nil, err
+		}
+
+		return prom.HeaderAuth(opts.AuthHeader, value), nil
+
+	default:
+		return nil, fmt.Errorf("unknown auth method '%s'", opts.Auth)
 	}
+}
 
-	if len(baseURL) == 0 {
-		return "", errors.New("one of --tenant or --server-url must be specified")
+// TLSFiles returns the file-based TLS settings.
+func (opts *ClientOptions) TLSFiles() prom.TLSFiles {
+	return prom.TLSFiles{
+		CAFile:             opts.CAFile,
+		CertFile:           opts.CertFile,
+		KeyFile:            opts.KeyFile,
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
 	}
-	return baseURL, nil
 }
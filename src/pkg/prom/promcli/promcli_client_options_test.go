@@ -0,0 +1,139 @@
+package promcli
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mmihic/promlib/src/pkg/prom"
+)
+
+func TestClientOptions_Authenticator(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600))
+
+	t.Setenv("PROM_API_TOKEN", "api-token")
+	t.Setenv("PROM_BEARER_TOKEN", "bearer-token")
+	t.Setenv("PROM_AUTH_HEADER_VALUE", "tenant-a")
+
+	for _, tt := range []struct {
+		name        string
+		opts        ClientOptions
+		header      string
+		expected    string
+		expectedErr string
+	}{
+		{
+			"defaults to api token",
+			ClientOptions{},
+			"API-Token", "api-token", "",
+		},
+		{
+			"basic auth",
+			ClientOptions{Auth: AuthBasic, BasicAuthUser: "admin", BasicAuthPasswordFile: passwordFile},
+			"Authorization", "Basic YWRtaW46czNjcmV0", "",
+		},
+		{
+			"basic auth without user",
+			ClientOptions{Auth: AuthBasic, BasicAuthPasswordFile: passwordFile},
+			"", "", "--basic-auth-user is required",
+		},
+		{
+			"bearer token from env",
+			ClientOptions{Auth: AuthBearer},
+			"Authorization", "Bearer bearer-token", "",
+		},
+		{
+			"bearer token from file",
+			ClientOptions{Auth: AuthBearer, BearerTokenFile: passwordFile},
+			"Authorization", "Bearer s3cret", "",
+		},
+		{
+			"custom header",
+			ClientOptions{Auth: AuthHeader, AuthHeader: "X-Scope-OrgID"},
+			"X-Scope-OrgID", "tenant-a", "",
+		},
+		{
+			"oauth2 without token url",
+			ClientOptions{Auth: AuthOAuth2, OAuth2ClientID: "promlib"},
+			"", "", "--oauth2-token-url and --oauth2-client-id are required",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := tt.opts.Authenticator()
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			h := http.Header{}
+			require.NoError(t, auth.Authenticate(context.TODO(), h))
+			assert.Equal(t, tt.expected, h.Get(tt.header))
+		})
+	}
+
+	auth, err := (&ClientOptions{Auth: AuthNone}).Authenticator()
+	require.NoError(t, err)
+	assert.Nil(t, auth)
+}
+
+func TestClientOptions_PromBackend(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		opts        ClientOptions
+		expected    prom.Backend
+		expectedErr string
+	}{
+		{
+			"defaults to the meta chronosphere tenant",
+			ClientOptions{},
+			prom.ChronosphereBackend("meta"), "",
+		},
+		{
+			"chronosphere server url",
+			ClientOptions{Backend: BackendChronosphere, PromServerURL: "https://example.com"},
+			prom.ChronosphereBackend(""), "",
+		},
+		{
+			"chronosphere server url and tenant",
+			ClientOptions{Backend: BackendChronosphere, PromServerURL: "https://example.com", SourceTenant: "meta"},
+			nil, "only one of --source-tenant or --prom-server-url",
+		},
+		{
+			"mimir",
+			ClientOptions{Backend: BackendMimir, PromServerURL: "https://mimir", SourceTenant: "team-a"},
+			prom.MimirBackend("team-a"), "",
+		},
+		{
+			"thanos",
+			ClientOptions{Backend: BackendThanos, PromServerURL: "https://thanos", ThanosNoDedup: true},
+			prom.ThanosBackend(prom.ThanosOptions{DisableDedup: true}), "",
+		},
+		{
+			"victoriametrics without server url",
+			ClientOptions{Backend: BackendVictoriaMetrics, SourceTenant: "42"},
+			nil, "--prom-server-url is required for the victoriametrics backend",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := tt.opts.PromBackend()
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, backend)
+		})
+	}
+}
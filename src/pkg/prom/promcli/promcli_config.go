@@ -0,0 +1,292 @@
+package promcli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv is the environment variable that overrides the location of
+// the config file.
+const ConfigFileEnv = "PROMCLI_CONFIG"
+
+// Config is the promcli config file, which holds named contexts in the
+// spirit of a kubeconfig. Each context supplies defaults for the client
+// flags, which are still overridden by flags given on the command line.
+type Config struct {
+	CurrentContext string              `yaml:"current-context,omitempty"`
+	Contexts       map[string]*Context `yaml:"contexts,omitempty"`
+
+	path    string
+	loadErr error
+}
+
+// Context is a named set of defaults for talking to a backend. Secrets are
+// never stored in the config file, only the files that contain them.
+type Context struct {
+	ServerURL string         `yaml:"server-url,omitempty"`
+	Backend   string         `yaml:"backend,omitempty"`
+	Tenant    string         `yaml:"tenant,omitempty"`
+	Thanos    *ThanosContext `yaml:"thanos,omitempty"`
+	Auth      *AuthContext   `yaml:"auth,omitempty"`
+	TLS       *TLSContext    `yaml:"tls,omitempty"`
+	Format    string         `yaml:"format,omitempty"`
+	Step      string         `yaml:"step,omitempty"`
+}
+
+// ThanosContext are the Thanos settings for a context.
+type ThanosContext struct {
+	PartialResponse bool `yaml:"partial-response,omitempty"`
+	NoDedup         bool `yaml:"no-dedup,omitempty"`
+}
+
+// AuthContext are the authentication settings for a context.
+type AuthContext struct {
+	Method                 string   `yaml:"method,omitempty"`
+	APITokenFile           string   `yaml:"api-token-file,omitempty"`
+	BasicAuthUser          string   `yaml:"basic-auth-user,omitempty"`
+	BasicAuthPasswordFile  string   `yaml:"basic-auth-password-file,omitempty"`
+	BearerTokenFile        string   `yaml:"bearer-token-file,omitempty"`
+	OAuth2TokenURL         string   `yaml:"oauth2-token-url,omitempty"`
+	OAuth2ClientID         string   `yaml:"oauth2-client-id,omitempty"`
+	OAuth2ClientSecretFile string   `yaml:"oauth2-client-secret-file,omitempty"`
+	OAuth2Scopes           []string `yaml:"oauth2-scopes,omitempty"`
+	Header                 string   `yaml:"header,omitempty"`
+	HeaderValueFile        string   `yaml:"header-value-file,omitempty"`
+}
+
+// TLSContext are the TLS settings for a context.
+type TLSContext struct {
+	CAFile             string `yaml:"ca-file,omitempty"`
+	CertFile           string `yaml:"cert-file,omitempty"`
+	KeyFile            string `yaml:"key-file,omitempty"`
+	ServerName         string `yaml:"server-name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty"`
+}
+
+// DefaultConfigFile returns the location of the config file: the value of
+// $PROMCLI_CONFIG if set, otherwise ~/.config/promcli/config.yaml.
+func DefaultConfigFile() (string, error) {
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to find home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".config", "promcli", "config.yaml"), nil
+}
+
+// LoadConfig loads the config file at the given path. A missing file is
+// treated as an empty config, so that it can be created by Save.
+//
+// If the file cannot be loaded, the error is returned along with an empty
+// config that remembers it, so that commands that do not depend on the
+// config still run: selecting a context from it returns the error, and it
+// is never saved over the broken file.
+func LoadConfig(path string) (*Config, error) {
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return &Config{path: path, loadErr: err}, err
+	}
+
+	return cfg, nil
+}
+
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{path: path}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Save writes the config back to the file it was loaded from.
+func (cfg *Config) Save() error {
+	if cfg.loadErr != nil {
+		return fmt.Errorf("not overwriting %s, which could not be loaded: %w", cfg.path, cfg.loadErr)
+	}
+
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(cfg.path, b, 0o600)
+}
+
+// Path returns the location of the config file.
+func (cfg *Config) Path() string {
+	return cfg.path
+}
+
+// ContextNames returns the names of all contexts, in sorted order.
+func (cfg *Config) ContextNames() []string {
+	names := make([]string, 0, len(cfg.Contexts))
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Context returns the named context, or the current context if name is
+// empty. Returns nil if name is empty and there is no current context,
+// which is always the case if the config file could not be loaded.
+func (cfg *Config) Context(name string) (*Context, error) {
+	if name == "" {
+		name = cfg.CurrentContext
+	}
+
+	if name == "" {
+		return nil, nil
+	}
+
+	if cfg.loadErr != nil {
+		return nil, cfg.loadErr
+	}
+
+	ctx, ok := cfg.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown context '%s' in %s", name, cfg.path)
+	}
+
+	return ctx, nil
+}
+
+// Use makes the named context the current context.
+func (cfg *Config) Use(name string) error {
+	if cfg.loadErr != nil {
+		return cfg.loadErr
+	}
+
+	if _, ok := cfg.Contexts[name]; !ok {
+		return fmt.Errorf("unknown context '%s' in %s", name, cfg.path)
+	}
+
+	cfg.CurrentContext = name
+	return nil
+}
+
+// Resolver returns a kong.Resolver that fills in flags from the context
+// named by --context, or from the current context. Flags given on the
+// command line take precedence. Commands without a --context flag, which
+// do not create clients, are left alone.
+func (cfg *Config) Resolver() kong.Resolver {
+	return kong.ResolverFunc(func(kctx *kong.Context, _ *kong.Path, flag *kong.Flag) (any, error) {
+		name, ok := selectedContext(kctx)
+		if !ok {
+			return nil, nil
+		}
+
+		ctx, err := cfg.Context(name)
+		if err != nil || ctx == nil {
+			return nil, err
+		}
+
+		value, ok := ctx.flags()[flag.Name]
+		if !ok {
+			return nil, nil
+		}
+
+		return value, nil
+	})
+}
+
+// selectedContext returns the value of the --context flag, and whether the
+// command has one.
+func selectedContext(kctx *kong.Context) (string, bool) {
+	for _, flag := range kctx.Flags() {
+		if flag.Name == "context" {
+			name, _ := kctx.FlagValue(flag).(string)
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// flags returns the values of the flags set by the context, keyed by flag
+// name.
+func (ctx *Context) flags() map[string]any {
+	flags := map[string]any{}
+	setString := func(name, value string) {
+		if value != "" {
+			flags[name] = value
+		}
+	}
+
+	setBool := func(name string, value bool) {
+		if value {
+			flags[name] = strconv.FormatBool(value)
+		}
+	}
+
+	setString("prom-server-url", ctx.ServerURL)
+	setString("backend", ctx.Backend)
+	setString("source-tenant", ctx.Tenant)
+	setString("format", ctx.Format)
+	setString("step", ctx.Step)
+
+	if ctx.Thanos != nil {
+		setBool("thanos-partial-response", ctx.Thanos.PartialResponse)
+		setBool("thanos-no-dedup", ctx.Thanos.NoDedup)
+	}
+
+	if auth := ctx.Auth; auth != nil {
+		setString("auth", auth.Method)
+		setString("prom-api-token-file", auth.APITokenFile)
+		setString("basic-auth-user", auth.BasicAuthUser)
+		setString("basic-auth-password-file", auth.BasicAuthPasswordFile)
+		setString("bearer-token-file", auth.BearerTokenFile)
+		setString("oauth2-token-url", auth.OAuth2TokenURL)
+		setString("oauth2-client-id", auth.OAuth2ClientID)
+		setString("oauth2-client-secret-file", auth.OAuth2ClientSecretFile)
+		setString("oauth2-scopes", strings.Join(auth.OAuth2Scopes, ","))
+		setString("auth-header", auth.Header)
+		setString("auth-header-value-file", auth.HeaderValueFile)
+	}
+
+	if tls := ctx.TLS; tls != nil {
+		setString("ca-file", tls.CAFile)
+		setString("cert-file", tls.CertFile)
+		setString("key-file", tls.KeyFile)
+		setString("server-name", tls.ServerName)
+		setBool("insecure-skip-verify", tls.InsecureSkipVerify)
+	}
+
+	return flags
+}
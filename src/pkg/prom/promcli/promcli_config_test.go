@@ -0,0 +1,161 @@
+package promcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+current-context: thanos
+contexts:
+  thanos:
+    server-url: https://thanos.example.com
+    backend: thanos
+    thanos:
+      partial-response: true
+    auth:
+      method: bearer
+      bearer-token-file: /etc/promcli/token
+    tls:
+      ca-file: /etc/promcli/ca.pem
+    step: 5m
+  mimir:
+    server-url: https://mimir.example.com/prometheus
+    backend: mimir
+    tenant: team-a
+    auth:
+      method: oauth2
+      oauth2-scopes: [read, write]
+`
+
+type testCommand struct {
+	ClientOptions
+
+	Step Duration `help:"step"`
+}
+
+func (cmd *testCommand) Run() error { return nil }
+
+func loadTestConfig(t *testing.T) *Config {
+	path := filepath.Join(t.TempDir(), "promcli", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	return cfg
+}
+
+func parseWithConfig(t *testing.T, cfg *Config, args ...string) (*testCommand, error) {
+	var cli struct {
+		Query testCommand `cmd:""`
+	}
+
+	parser, err := kong.New(&cli, kong.Resolvers(cfg.Resolver()))
+	require.NoError(t, err)
+
+	_, err = parser.Parse(append([]string{"query"}, args...))
+	return &cli.Query, err
+}
+
+func TestConfig_Resolver(t *testing.T) {
+	t.Setenv("PROMCLI_CONTEXT", "")
+	cfg := loadTestConfig(t)
+
+	// Defaults to the current context
+	cmd, err := parseWithConfig(t, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "https://thanos.example.com", cmd.PromServerURL)
+	assert.Equal(t, BackendThanos, cmd.Backend)
+	assert.True(t, cmd.ThanosPartialResponse)
+	assert.Equal(t, AuthBearer, cmd.Auth)
+	assert.Equal(t, "/etc/promcli/token", cmd.BearerTokenFile)
+	assert.Equal(t, "/etc/promcli/ca.pem", cmd.CAFile)
+	assert.Equal(t, Duration(5*time.Minute), cmd.Step)
+
+	// Flags on the command line take precedence
+	cmd, err = parseWithConfig(t, cfg, "--step=1m", "--prom-server-url=https://other.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "https://other.example.com", cmd.PromServerURL)
+	assert.Equal(t, Duration(time.Minute), cmd.Step)
+
+	// A named context replaces the current one entirely
+	cmd, err = parseWithConfig(t, cfg, "--context=mimir")
+	require.NoError(t, err)
+	assert.Equal(t, "https://mimir.example.com/prometheus", cmd.PromServerURL)
+	assert.Equal(t, BackendMimir, cmd.Backend)
+	assert.Equal(t, "team-a", cmd.SourceTenant)
+	assert.Equal(t, AuthOAuth2, cmd.Auth)
+	assert.Equal(t, []string{"read", "write"}, cmd.OAuth2Scopes)
+	assert.False(t, cmd.ThanosPartialResponse)
+	assert.Equal(t, Duration(0), cmd.Step)
+
+	_, err = parseWithConfig(t, cfg, "--context=missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown context 'missing'")
+}
+
+func TestConfig_UseAndSave(t *testing.T) {
+	cfg := loadTestConfig(t)
+	assert.Equal(t, []string{"mimir", "thanos"}, cfg.ContextNames())
+
+	require.Error(t, cfg.Use("missing"))
+	require.NoError(t, cfg.Use("mimir"))
+	require.NoError(t, cfg.Save())
+
+	reloaded, err := LoadConfig(cfg.Path())
+	require.NoError(t, err)
+	assert.Equal(t, "mimir", reloaded.CurrentContext)
+	assert.Equal(t, cfg.Contexts, reloaded.Contexts)
+}
+
+func TestLoadConfig_Missing(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Contexts)
+
+	ctx, err := cfg.Context("")
+	require.NoError(t, err)
+	assert.Nil(t, ctx)
+}
+
+func TestLoadConfig_UnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("contexts:\n  a:\n    url: http://localhost\n"), 0o600))
+
+	_, err := LoadConfig(path)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	corrupt := []byte("current-context: thanos\ncontexts:\n  thanos:\n    server-url: [https://")
+	require.NoError(t, os.WriteFile(path, corrupt, 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.Error(t, err)
+	require.NotNil(t, cfg)
+
+	// Commands that do not name a context still run, without defaults
+	cmd, err := parseWithConfig(t, cfg, "--prom-server-url=https://other.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "https://other.example.com", cmd.PromServerURL)
+
+	// Naming a context, or changing the file, reports why it was not loaded
+	_, err = parseWithConfig(t, cfg, "--context=thanos")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to parse")
+
+	require.Error(t, cfg.Use("thanos"))
+	require.Error(t, cfg.Save())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, corrupt, b)
+}
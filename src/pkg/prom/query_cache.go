@@ -0,0 +1,396 @@
+package prom
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+	"go.uber.org/zap"
+
+	"github.com/mmihic/promlib/src/pkg/prom/querylog"
+)
+
+const (
+	// DefaultCacheSettleTime is how far in the past a query window must end
+	// before its results are treated as immutable.
+	DefaultCacheSettleTime = time.Hour
+
+	// DefaultCacheRecentTTL is how long results are cached for windows that
+	// end less than the settle time ago.
+	DefaultCacheRecentTTL = time.Minute
+)
+
+// A Cache stores encoded query results by key. Keys are hex strings, safe
+// to use as file names. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for the key, and whether there was one.
+	Get(key string) ([]byte, bool, error)
+
+	// Set stores the value for the key.
+	Set(key string, value []byte) error
+}
+
+// CacheOpt are options when creating a caching client.
+type CacheOpt func(*cachingClient)
+
+// WithCacheSettleTime sets how far in the past a query window must end
+// before its results are treated as immutable. Samples near the present
+// can still change, e.g. as late samples arrive or rules are evaluated.
+// Defaults to DefaultCacheSettleTime.
+func WithCacheSettleTime(d time.Duration) CacheOpt {
+	return func(c *cachingClient) {
+		c.settleTime = d
+	}
+}
+
+// WithCacheRecentTTL sets how long results are cached for windows that end
+// within the settle time. Zero disables caching of recent windows.
+// Defaults to DefaultCacheRecentTTL.
+func WithCacheRecentTTL(ttl time.Duration) CacheOpt {
+	return func(c *cachingClient) {
+		c.recentTTL = ttl
+	}
+}
+
+// WithCacheHistoricalTTL sets how long results are cached for windows that
+// ended before the settle time. Defaults to zero, which caches them forever.
+func WithCacheHistoricalTTL(ttl time.Duration) CacheOpt {
+	return func(c *cachingClient) {
+		c.historicalTTL = ttl
+	}
+}
+
+// WithCacheQueryLog sets a Logger for cache hits and misses.
+func WithCacheQueryLog(log *zap.Logger, logResponses bool) CacheOpt {
+	return func(c *cachingClient) {
+		c.queryLog = querylog.New(log, logResponses)
+	}
+}
+
+// WithCacheNamespace sets a namespace included in every cache key, so that
+// clients that see different data can share a cache. Clients created by
+// NewClient are already keyed by their server, backend, headers and
+// credentials, so this is only needed for other Clients, or for clients
+// using a custom Authenticator, which cannot be told apart otherwise.
+func WithCacheNamespace(ns string) CacheOpt {
+	return func(c *cachingClient) {
+		c.namespace = ns
+	}
+}
+
+// WithCacheClock sets the clock used to compute TTLs.
+func WithCacheClock(clock clockwork.Clock) CacheOpt {
+	return func(c *cachingClient) {
+		c.clock = clock
+	}
+}
+
+// NewCachingClient returns a Client that caches the results of range and
+// instant queries in the given cache, keyed by the identity of the client,
+// the normalized query, its time parameters and step, and the settings
+// that control how it is executed, such as splitting and timeouts. Monthly and
+// period queries are cached through their per-period queries, so closed
+// periods are served from the cache while the current one is refreshed.
+// Instant queries without an explicit time, queries that request
+// statistics and streamed queries bypass the cache, and results with
+// warnings are never cached. All other methods are passed through.
+func NewCachingClient(c Client, cache Cache, opts ...CacheOpt) Client {
+	cc := &cachingClient{
+		Client:     c,
+		cache:      cache,
+		settleTime: DefaultCacheSettleTime,
+		recentTTL:  DefaultCacheRecentTTL,
+		clock:      clockwork.NewRealClock(),
+	}
+
+	if inner, ok := c.(*client); ok {
+		cc.identity = inner.cacheIdentity()
+	}
+
+	for _, opt := range opts {
+		opt(cc)
+	}
+
+	if cc.queryLog == nil {
+		cc.queryLog = querylog.NewNop()
+	}
+
+	return cc
+}
+
+type cachingClient struct {
+	Client
+	cache         Cache
+	identity      string
+	namespace     string
+	settleTime    time.Duration
+	recentTTL     time.Duration
+	historicalTTL time.Duration
+	queryLog      querylog.Logger
+	clock         clockwork.Clock
+}
+
+func (c *cachingClient) RangeQuery(q string) RangeQuery {
+	return cachingRangeQuery{
+		RangeQuery: c.Client.RangeQuery(q),
+		c:          c,
+		q:          q,
+		step:       defaultStep,
+	}
+}
+
+func (c *cachingClient) InstantQuery(q string) InstantQuery {
+	return cachingInstantQuery{
+		InstantQuery: c.Client.InstantQuery(q),
+		c:            c,
+		q:            q,
+	}
+}
+
+func (c *cachingClient) MonthlyQuery(q string) MonthlyQuery {
+	return newMonthlyQuery(c, q)
+}
+
+func (c *cachingClient) PeriodQuery(q string, period Period) PeriodQuery {
+	return newPeriodQuery(c, q, period)
+}
+
+// cacheEntry is the encoded form of a cached result.
+type cacheEntry struct {
+	Expires *time.Time `json:"expires,omitempty"`
+	Result  *Result    `json:"result"`
+}
+
+// do returns the cached result for the key if there is an unexpired one,
+// otherwise runs the query and caches its result until a TTL based on the
+// end of the query window.
+func (c *cachingClient) do(
+	ctx context.Context, log querylog.LoggedQuery, key string, end time.Time, fn func() (*Result, error),
+) (*Result, error) {
+	now := c.clock.Now()
+
+	// The cache is only an optimization, so failures to read or write it
+	// are logged and the query is answered by the server
+	b, ok, err := c.cache.Get(key)
+	if err != nil {
+		log.QueryCacheFailed(err)
+	}
+
+	if err == nil && ok {
+		var entry cacheEntry
+		// Entries that cannot be decoded, e.g. from an older version, are
+		// treated as misses and overwritten
+		if err := json.Unmarshal(b, &entry); err == nil && entry.Result != nil &&
+			(entry.Expires == nil || now.Before(*entry.Expires)) {
+			log.QueryCacheHit()
+			log.QueryComplete(entry.Result)
+			return entry.Result, nil
+		}
+	}
+
+	log.QueryCacheMiss()
+	r, err := fn()
+	if err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	// Warnings usually mean a partial or truncated result, e.g. when some
+	// stores were unavailable, which a retry may complete
+	if len(r.Warnings) != 0 {
+		log.QueryComplete(r)
+		return r, nil
+	}
+
+	entry := cacheEntry{Result: r}
+	if now.Sub(end) < c.settleTime {
+		if c.recentTTL == 0 {
+			log.QueryComplete(r)
+			return r, nil
+		}
+
+		expires := now.Add(c.recentTTL)
+		entry.Expires = &expires
+	} else if c.historicalTTL != 0 {
+		expires := now.Add(c.historicalTTL)
+		entry.Expires = &expires
+	}
+
+	b, err = json.Marshal(entry)
+	if err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if err := c.cache.Set(key, b); err != nil {
+		log.QueryCacheFailed(err)
+	}
+
+	log.QueryComplete(r)
+	return r, nil
+}
+
+type cachingRangeQuery struct {
+	RangeQuery
+	c           *cachingClient
+	q           string
+	start, end  time.Time
+	step        model.Duration
+	splitBy     time.Duration
+	autoSplit   bool
+	maxParallel int
+	timeout     time.Duration
+	stats       bool
+}
+
+func (q cachingRangeQuery) Start(t time.Time) RangeQuery {
+	q.RangeQuery, q.start = q.RangeQuery.Start(t), t
+	return q
+}
+
+func (q cachingRangeQuery) End(t time.Time) RangeQuery {
+	q.RangeQuery, q.end = q.RangeQuery.End(t), t
+	return q
+}
+
+func (q cachingRangeQuery) Step(step model.Duration) RangeQuery {
+	q.RangeQuery, q.step = q.RangeQuery.Step(step), step
+	return q
+}
+
+func (q cachingRangeQuery) SplitBy(d time.Duration) RangeQuery {
+	q.RangeQuery, q.splitBy, q.autoSplit = q.RangeQuery.SplitBy(d), d, false
+	return q
+}
+
+func (q cachingRangeQuery) AutoSplit() RangeQuery {
+	q.RangeQuery, q.splitBy, q.autoSplit = q.RangeQuery.AutoSplit(), 0, true
+	return q
+}
+
+func (q cachingRangeQuery) MaxParallel(n int) RangeQuery {
+	q.RangeQuery, q.maxParallel = q.RangeQuery.MaxParallel(n), n
+	return q
+}
+
+func (q cachingRangeQuery) Timeout(d time.Duration) RangeQuery {
+	q.RangeQuery, q.timeout = q.RangeQuery.Timeout(d), d
+	return q
+}
+
+func (q cachingRangeQuery) WithStats() RangeQuery {
+	q.RangeQuery, q.stats = q.RangeQuery.WithStats(), true
+	return q
+}
+
+func (q cachingRangeQuery) Do(ctx context.Context) (*Result, error) {
+	if q.stats || q.start.IsZero() || q.end.IsZero() {
+		return q.RangeQuery.Do(ctx)
+	}
+
+	log := q.c.queryLog.BeginQuery("cached-range-query",
+		zap.String("query", q.q),
+		zap.Time("start", q.start),
+		zap.Time("end", q.end),
+		zap.Duration("step", time.Duration(q.step)))
+
+	// Splitting and timeouts can change what the server returns, e.g. when
+	// a shard hits a limit, so results are only shared between queries
+	// executed the same way
+	key := q.c.cacheKey("range", q.q, formatTime(q.start), formatTime(q.end), q.step.String(),
+		q.splitBy.String(), strconv.FormatBool(q.autoSplit), strconv.Itoa(q.maxParallel), q.timeout.String())
+	return q.c.do(ctx, log, key, q.end, func() (*Result, error) {
+		return q.RangeQuery.Do(ctx)
+	})
+}
+
+type cachingInstantQuery struct {
+	InstantQuery
+	c       *cachingClient
+	q       string
+	t       time.Time
+	timeout time.Duration
+	stats   bool
+}
+
+func (q cachingInstantQuery) Time(t time.Time) InstantQuery {
+	q.InstantQuery, q.t = q.InstantQuery.Time(t), t
+	return q
+}
+
+func (q cachingInstantQuery) Timeout(d time.Duration) InstantQuery {
+	q.InstantQuery, q.timeout = q.InstantQuery.Timeout(d), d
+	return q
+}
+
+func (q cachingInstantQuery) WithStats() InstantQuery {
+	q.InstantQuery, q.stats = q.InstantQuery.WithStats(), true
+	return q
+}
+
+func (q cachingInstantQuery) Do(ctx context.Context) (*Result, error) {
+	if q.stats || q.t.IsZero() {
+		return q.InstantQuery.Do(ctx)
+	}
+
+	log := q.c.queryLog.BeginQuery("cached-instant-query",
+		zap.String("query", q.q),
+		zap.Time("time", q.t))
+
+	key := q.c.cacheKey("instant", q.q, formatTime(q.t), q.timeout.String())
+	return q.c.do(ctx, log, key, q.t, func() (*Result, error) {
+		return q.InstantQuery.Do(ctx)
+	})
+}
+
+// cacheKey returns the cache key for a query of the given kind, with the
+// query normalized so that formatting differences share an entry.
+func (c *cachingClient) cacheKey(kind, q string, params ...string) string {
+	h := sha256.New()
+	for _, s := range []string{c.identity, c.namespace, kind, normalizeQuery(q)} {
+		h.Write([]byte(s + "\n"))
+	}
+
+	h.Write([]byte(strings.Join(params, "\n")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheIdentity returns what determines the data visible to a client: the
+// server it queries, how the backend maps queries onto it, the headers it
+// sends and who it authenticates as.
+func (c *client) cacheIdentity() string {
+	var b strings.Builder
+	b.WriteString(c.baseURL + withQuery(c.backend.Path(pathRangeQuery), c.params(pathRangeQuery, nil)) + "\n")
+
+	names := make([]string, 0, len(c.headers))
+	for name := range c.headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(name + ": " + strings.Join(c.headers[name], ", ") + "\n")
+	}
+
+	b.WriteString(authIdentity(c.auth))
+	return b.String()
+}
+
+// normalizeQuery returns the canonical form of a PromQL query, or the
+// query itself if it cannot be parsed.
+func normalizeQuery(q string) string {
+	expr, err := parser.ParseExpr(q)
+	if err != nil {
+		return q
+	}
+
+	return expr.String()
+}
@@ -0,0 +1,68 @@
+package prom
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// NewDiskCache returns a Cache that stores each result in a file in the
+// given directory, creating the directory if needed. Results are kept
+// until they are replaced or the files are removed, so that historical
+// results survive across runs.
+func NewDiskCache(dir string) (Cache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return diskCache{dir: dir}, nil
+}
+
+type diskCache struct {
+	dir string
+}
+
+func (c diskCache) Get(key string) ([]byte, bool, error) {
+	b, err := os.ReadFile(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
+
+func (c diskCache) Set(key string, value []byte) error {
+	// Write to a temporary file and rename it into place, so that readers
+	// never see a partially written result
+	f, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(value); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Rename(f.Name(), c.path(key)); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+func (c diskCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
@@ -0,0 +1,61 @@
+package prom
+
+import (
+	"container/list"
+	"sync"
+)
+
+// NewLRUCache returns an in-memory Cache holding up to maxEntries results,
+// evicting the least recently used result when full.
+func NewLRUCache(maxEntries int) Cache {
+	return &lruCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+type lruCache struct {
+	mut        sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+func (c *lruCache) Get(key string) ([]byte, bool, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true, nil
+}
+
+func (c *lruCache) Set(key string, value []byte) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+
+	return nil
+}
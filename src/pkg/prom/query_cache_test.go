@@ -0,0 +1,379 @@
+package prom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mmihic/golib/src/pkg/timex"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// countingServer returns a server that answers range and instant queries
+// with a single sample, counting requests.
+func countingServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests.Add(1)
+
+		if r.URL.Path == pathInstantQuery {
+			_, _ = fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "vector", "result": [
+  {"metric": {"job": "api"}, "value": [%d, "1"]}
+]}}`, time.Now().Unix())
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "matrix", "result": [
+  {"metric": {"job": "api"}, "values": [[%s, "1"]]}
+]}}`, r.Form.Get("start"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestCachingClient_RangeQuery(t *testing.T) {
+	srv, requests := countingServer(t)
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	var (
+		clock = clockwork.NewFakeClockAt(time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC))
+		cache = NewLRUCache(100)
+	)
+
+	c = NewCachingClient(c, cache, WithCacheClock(clock))
+
+	query := func(q string, end time.Time) *Result {
+		r, err := c.RangeQuery(q).
+			Start(end.Add(-time.Hour)).
+			End(end).
+			Step(model.Duration(time.Minute)).
+			Do(context.TODO())
+		require.NoError(t, err)
+		return r
+	}
+
+	// Historical windows are cached indefinitely, keyed by the normalized query
+	historical := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	expected := query("sum(up) by (job)", historical)
+	assert.Equal(t, expected, query("sum by (job) (up)", historical))
+	assert.Equal(t, int32(1), requests.Load())
+
+	clock.Advance(365 * 24 * time.Hour)
+	assert.Equal(t, expected, query("sum(up) by (job)", historical))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Recent windows expire after the recent TTL
+	recent := clock.Now().Add(-time.Minute)
+	query("sum(up)", recent)
+	query("sum(up)", recent)
+	assert.Equal(t, int32(2), requests.Load())
+
+	clock.Advance(DefaultCacheRecentTTL)
+	query("sum(up)", recent)
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Queries executed differently do not share results
+	_, err = c.RangeQuery("sum(up) by (job)").
+		Start(historical.Add(-time.Hour)).
+		End(historical).
+		Step(model.Duration(time.Minute)).
+		SplitBy(30 * time.Minute).
+		Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), requests.Load())
+
+	_, err = c.RangeQuery("sum(up) by (job)").
+		Start(historical.Add(-time.Hour)).
+		End(historical).
+		Step(model.Duration(time.Minute)).
+		Timeout(time.Second).
+		Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), requests.Load())
+
+	// Statistics are never served from the cache
+	_, err = c.RangeQuery("sum(up) by (job)").
+		Start(historical.Add(-time.Hour)).
+		End(historical).
+		Step(model.Duration(time.Minute)).
+		WithStats().
+		Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), requests.Load())
+}
+
+func TestCachingClient_MonthlyQuery(t *testing.T) {
+	srv, requests := countingServer(t)
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	// Each month is evaluated at its start, so March has not settled yet
+	clock := clockwork.NewFakeClockAt(time.Date(2024, 3, 1, 0, 30, 0, 0, time.UTC))
+	c = NewCachingClient(c, NewLRUCache(100), WithCacheClock(clock))
+
+	query := func() {
+		_, err := c.MonthlyQuery("sum(up)").
+			Start(timex.MustParseMonthYear("2024-01")).
+			End(timex.MustParseMonthYear("2024-03")).
+			Do(context.TODO())
+		require.NoError(t, err)
+	}
+
+	query()
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Only the current month, which has not settled, is re-issued
+	clock.Advance(time.Hour)
+	query()
+	assert.Equal(t, int32(4), requests.Load())
+}
+
+func TestCachingClient_MonthlyQueryAtMonthEnd(t *testing.T) {
+	srv, requests := countingServer(t)
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	clock := clockwork.NewFakeClockAt(time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC))
+	c = NewCachingClient(c, NewLRUCache(100), WithCacheClock(clock))
+
+	query := func() {
+		_, err := c.MonthlyQuery("sum(sum_over_time(up[$__range]))").
+			Start(timex.MustParseMonthYear("2024-01")).
+			End(timex.MustParseMonthYear("2024-03")).
+			AtMonthEnd().
+			Do(context.TODO())
+		require.NoError(t, err)
+	}
+
+	query()
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Only the current month, which ends in the future, is re-issued
+	clock.Advance(time.Hour)
+	query()
+	assert.Equal(t, int32(4), requests.Load())
+}
+
+func TestCachingClient_InstantQuery(t *testing.T) {
+	srv, requests := countingServer(t)
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	var (
+		core, logs = observer.New(zap.InfoLevel)
+		clock      = clockwork.NewFakeClockAt(time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC))
+		at         = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	cache, err := NewDiskCache(t.TempDir())
+	require.NoError(t, err)
+
+	c = NewCachingClient(c, cache, WithCacheClock(clock), WithCacheQueryLog(zap.New(core), false))
+	for i := 0; i < 2; i++ {
+		_, err := c.InstantQuery("up").Time(at).Do(context.TODO())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), requests.Load())
+
+	var hits []bool
+	for _, entry := range logs.FilterMessage("cached-instant-query").All() {
+		if hit, ok := entry.ContextMap()["cache_hit"]; ok {
+			hits = append(hits, hit.(bool))
+		}
+	}
+
+	assert.Equal(t, []bool{false, true}, hits)
+
+	// Queries without an explicit time are evaluated at the server's
+	// current time, so are never cached
+	for i := 0; i < 2; i++ {
+		_, err := c.InstantQuery("up").Do(context.TODO())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestCachingClient_KeyedByClient(t *testing.T) {
+	srv, requests := countingServer(t)
+
+	var (
+		cache = NewLRUCache(100)
+		clock = clockwork.NewFakeClockAt(time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC))
+		at    = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	query := func(opts []ClientOpt, cacheOpts ...CacheOpt) {
+		c, err := NewClient(srv.URL, opts...)
+		require.NoError(t, err)
+
+		c = NewCachingClient(c, cache, append(cacheOpts, WithCacheClock(clock))...)
+		_, err = c.InstantQuery("up").Time(at).Do(context.TODO())
+		require.NoError(t, err)
+	}
+
+	// Clients that see different data do not share results
+	for _, opts := range [][]ClientOpt{
+		{WithBackend(MimirBackend("team-a"))},
+		{WithBackend(MimirBackend("team-b"))},
+		{WithBackend(MimirBackend("team-a")), WithAuth(BasicAuth("alice", "secret"))},
+		{WithBackend(MimirBackend("team-a")), WithAuth(BasicAuth("bob", "secret"))},
+		{WithHeader("X-Tenant", "team-c")},
+	} {
+		query(opts)
+	}
+
+	assert.Equal(t, int32(5), requests.Load())
+
+	query([]ClientOpt{WithBackend(MimirBackend("team-b"))})
+	assert.Equal(t, int32(5), requests.Load())
+
+	query([]ClientOpt{WithBackend(MimirBackend("team-b"))}, WithCacheNamespace("other"))
+	assert.Equal(t, int32(6), requests.Load())
+}
+
+func TestCachingClient_SkipsWarnings(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"status": "success", "warnings": ["store unavailable"], ` +
+			`"data": {"resultType": "vector", "result": []}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	c = NewCachingClient(c, NewLRUCache(100))
+	for i := 0; i < 2; i++ {
+		r, err := c.InstantQuery("up").Time(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)).Do(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, Warnings{"store unavailable"}, r.Warnings)
+	}
+
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+// failingCache is a Cache whose reads and writes always fail.
+type failingCache struct{}
+
+func (failingCache) Get(string) ([]byte, bool, error) { return nil, false, errors.New("disk full") }
+func (failingCache) Set(string, []byte) error         { return errors.New("disk full") }
+
+func TestCachingClient_CacheFailures(t *testing.T) {
+	srv, requests := countingServer(t)
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	core, logs := observer.New(zap.InfoLevel)
+	c = NewCachingClient(c, failingCache{}, WithCacheQueryLog(zap.New(core), false))
+
+	// Queries are answered by the server, with the failures logged
+	for i := 0; i < 2; i++ {
+		_, err := c.InstantQuery("up").Time(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)).Do(context.TODO())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(2), requests.Load())
+
+	assert.Equal(t, 4, logs.FilterFieldKey("cache_error").Len())
+}
+
+func TestCachingClient_SplitSettingsReplaceEachOther(t *testing.T) {
+	srv, requests := countingServer(t)
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	c = NewCachingClient(c, NewLRUCache(100))
+	historical := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	query := func(q RangeQuery) {
+		_, err := q.Start(historical.Add(-time.Hour)).
+			End(historical).
+			Step(model.Duration(time.Minute)).
+			Do(context.TODO())
+		require.NoError(t, err)
+	}
+
+	query(c.RangeQuery("sum(up)").AutoSplit())
+	sent := requests.Load()
+
+	// The later setting wins, so these are the same query as above
+	query(c.RangeQuery("sum(up)").SplitBy(30 * time.Minute).AutoSplit())
+	assert.Equal(t, sent, requests.Load())
+
+	query(c.RangeQuery("sum(up)").SplitBy(30 * time.Minute))
+	sent = requests.Load()
+
+	query(c.RangeQuery("sum(up)").AutoSplit().SplitBy(30 * time.Minute))
+	assert.Equal(t, sent, requests.Load())
+}
+
+func TestLRUCache_Evicts(t *testing.T) {
+	cache := NewLRUCache(2)
+	require.NoError(t, cache.Set("a", []byte("1")))
+	require.NoError(t, cache.Set("b", []byte("2")))
+
+	_, ok, err := cache.Get("a")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// b is now the least recently used
+	require.NoError(t, cache.Set("c", []byte("3")))
+
+	_, ok, err = cache.Get("b")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for key, expected := range map[string]string{"a": "1", "c": "3"} {
+		value, ok, err := cache.Get(key)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, expected, string(value))
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir)
+	require.NoError(t, err)
+
+	_, ok, err := cache.Get("a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set("a", []byte("1")))
+	require.NoError(t, cache.Set("a", []byte("2")))
+
+	// Entries survive reopening the cache, with no temporary files left behind
+	cache, err = NewDiskCache(dir)
+	require.NoError(t, err)
+
+	value, ok, err := cache.Get("a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "2", string(value))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
@@ -0,0 +1,118 @@
+package prom
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap/zapcore"
+)
+
+// Stats are the per-query statistics returned by Prometheus when a query
+// is issued WithStats.
+type Stats struct {
+	Timings StatsTimings `json:"timings" yaml:"timings"`
+	Samples StatsSamples `json:"samples" yaml:"samples"`
+}
+
+// StatsTimings are the time spent in each phase of query execution, in
+// seconds.
+type StatsTimings struct {
+	EvalTotalTime        float64 `json:"evalTotalTime" yaml:"evalTotalTime"`
+	ResultSortTime       float64 `json:"resultSortTime" yaml:"resultSortTime"`
+	QueryPreparationTime float64 `json:"queryPreparationTime" yaml:"queryPreparationTime"`
+	InnerEvalTime        float64 `json:"innerEvalTime" yaml:"innerEvalTime"`
+	ExecQueueTime        float64 `json:"execQueueTime" yaml:"execQueueTime"`
+	ExecTotalTime        float64 `json:"execTotalTime" yaml:"execTotalTime"`
+}
+
+// StatsSamples are the number of samples touched by the query.
+type StatsSamples struct {
+	TotalQueryableSamplesPerStep []StepStat `json:"totalQueryableSamplesPerStep,omitempty" yaml:"totalQueryableSamplesPerStep,omitempty"`
+	TotalQueryableSamples        int64      `json:"totalQueryableSamples" yaml:"totalQueryableSamples"`
+	PeakSamples                  int64      `json:"peakSamples" yaml:"peakSamples"`
+}
+
+// A StepStat is the number of samples loaded for a single step of a query.
+type StepStat struct {
+	Timestamp model.Time
+	Value     int64
+}
+
+// MarshalJSON marshals the step stat as a [timestamp, value] pair.
+func (s StepStat) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("[%s,%d]", s.Timestamp, s.Value)), nil
+}
+
+// UnmarshalJSON unmarshals the step stat from a [timestamp, value] pair.
+func (s *StepStat) UnmarshalJSON(b []byte) error {
+	tmp := []any{&s.Timestamp, &s.Value}
+	wantLen := len(tmp)
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+
+	if len(tmp) != wantLen {
+		return fmt.Errorf("wrong number of fields: %d != %d", len(tmp), wantLen)
+	}
+
+	return nil
+}
+
+// MarshalLogObject logs the summary statistics, omitting the per-step
+// counts.
+func (s *Stats) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddFloat64("eval_total_time", s.Timings.EvalTotalTime)
+	enc.AddFloat64("result_sort_time", s.Timings.ResultSortTime)
+	enc.AddFloat64("query_preparation_time", s.Timings.QueryPreparationTime)
+	enc.AddFloat64("inner_eval_time", s.Timings.InnerEvalTime)
+	enc.AddFloat64("exec_queue_time", s.Timings.ExecQueueTime)
+	enc.AddFloat64("exec_total_time", s.Timings.ExecTotalTime)
+	enc.AddInt64("total_queryable_samples", s.Samples.TotalQueryableSamples)
+	enc.AddInt64("peak_samples", s.Samples.PeakSamples)
+	return nil
+}
+
+// mergeStats combines the statistics of queries run as parts of a single
+// logical query. Timings and totals are summed, while peak samples is the
+// largest peak of any part.
+func mergeStats(stats ...*Stats) *Stats {
+	var merged *Stats
+	for _, s := range stats {
+		if s == nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = &Stats{}
+		}
+
+		merged.Timings.EvalTotalTime += s.Timings.EvalTotalTime
+		merged.Timings.ResultSortTime += s.Timings.ResultSortTime
+		merged.Timings.QueryPreparationTime += s.Timings.QueryPreparationTime
+		merged.Timings.InnerEvalTime += s.Timings.InnerEvalTime
+		merged.Timings.ExecQueueTime += s.Timings.ExecQueueTime
+		merged.Timings.ExecTotalTime += s.Timings.ExecTotalTime
+		merged.Samples.TotalQueryableSamples += s.Samples.TotalQueryableSamples
+		if s.Samples.PeakSamples > merged.Samples.PeakSamples {
+			merged.Samples.PeakSamples = s.Samples.PeakSamples
+		}
+
+		merged.Samples.TotalQueryableSamplesPerStep = append(merged.Samples.TotalQueryableSamplesPerStep,
+			s.Samples.TotalQueryableSamplesPerStep...)
+	}
+
+	if merged != nil && len(merged.Samples.TotalQueryableSamplesPerStep) != 0 {
+		// Shards share their boundary step, so drop the duplicates
+		merged.Samples.TotalQueryableSamplesPerStep = dedupeByTimestamp(merged.Samples.TotalQueryableSamplesPerStep,
+			func(s StepStat) model.Time {
+				return s.Timestamp
+			})
+	}
+
+	return merged
+}
+
+var (
+	_ zapcore.ObjectMarshaler = &Stats{}
+)
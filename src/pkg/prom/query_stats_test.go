@@ -0,0 +1,50 @@
+package prom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeStats(t *testing.T) {
+	assert.Nil(t, mergeStats(nil, nil))
+
+	merged := mergeStats(
+		&Stats{
+			Timings: StatsTimings{EvalTotalTime: 0.5, ExecTotalTime: 0.75},
+			Samples: StatsSamples{
+				TotalQueryableSamplesPerStep: []StepStat{
+					{Timestamp: 1000, Value: 10},
+					{Timestamp: 2000, Value: 20},
+				},
+				TotalQueryableSamples: 30,
+				PeakSamples:           20,
+			},
+		},
+		nil,
+		&Stats{
+			Timings: StatsTimings{EvalTotalTime: 0.25, ExecTotalTime: 0.5},
+			Samples: StatsSamples{
+				TotalQueryableSamplesPerStep: []StepStat{
+					{Timestamp: 2000, Value: 20},
+					{Timestamp: 3000, Value: 5},
+				},
+				TotalQueryableSamples: 25,
+				PeakSamples:           15,
+			},
+		},
+	)
+
+	assert.Equal(t, &Stats{
+		Timings: StatsTimings{EvalTotalTime: 0.75, ExecTotalTime: 1.25},
+		Samples: StatsSamples{
+			TotalQueryableSamplesPerStep: []StepStat{
+				{Timestamp: 1000, Value: 10},
+				{Timestamp: 2000, Value: 20},
+				{Timestamp: 3000, Value: 5},
+			},
+			TotalQueryableSamples: 55,
+			PeakSamples:           20,
+		},
+	}, merged)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jonboulle/clockwork"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // A LoggedQuery is an in-flight query.
@@ -18,6 +19,25 @@ type LoggedQuery interface {
 
 	// QueryFailed is called if the query fails.
 	QueryFailed(err error)
+
+	// QueryRetrying is called when an attempt fails and the query will be
+	// retried after the given delay.
+	QueryRetrying(attempt int, delay time.Duration, err error)
+
+	// QueryStats is called with the execution statistics reported by the
+	// server for the query.
+	QueryStats(stats zapcore.ObjectMarshaler)
+
+	// QueryCacheHit is called when the result is served from a cache.
+	QueryCacheHit()
+
+	// QueryCacheMiss is called when the result is not in a cache and the
+	// query is sent to the server.
+	QueryCacheMiss()
+
+	// QueryCacheFailed is called when reading or writing a cache fails.
+	// The query itself continues without the cache.
+	QueryCacheFailed(err error)
 }
 
 // A Logger logs queries.
@@ -33,8 +53,13 @@ func NewNop() Logger {
 
 type nopLoggedQuery struct{}
 
-func (q nopLoggedQuery) QueryComplete(_ any) {}
-func (q nopLoggedQuery) QueryFailed(_ error) {}
+func (q nopLoggedQuery) QueryComplete(_ any)                           {}
+func (q nopLoggedQuery) QueryFailed(_ error)                           {}
+func (q nopLoggedQuery) QueryRetrying(_ int, _ time.Duration, _ error) {}
+func (q nopLoggedQuery) QueryStats(_ zapcore.ObjectMarshaler)          {}
+func (q nopLoggedQuery) QueryCacheHit()                                {}
+func (q nopLoggedQuery) QueryCacheMiss()                               {}
+func (q nopLoggedQuery) QueryCacheFailed(_ error)                      {}
 
 type nopLogger struct{}
 
@@ -115,8 +140,65 @@ func (q loggedQuery) QueryComplete(result any) {
 
 func (q loggedQuery) QueryFailed(err error) {
 	if ce := q.logger.log.Check(zap.InfoLevel, q.queryType); ce != nil {
-		ce.Write(zap.Uint64("query_id", q.id))
-		ce.Write(q.fields...)
-		ce.Write(zap.Error(err))
+		fields := append([]zap.Field{
+			zap.Uint64("query_id", q.id),
+			zap.Error(err),
+		}, q.fields...)
+
+		ce.Write(fields...)
+	}
+}
+
+func (q loggedQuery) QueryRetrying(attempt int, delay time.Duration, err error) {
+	if ce := q.logger.log.Check(zap.InfoLevel, q.queryType); ce != nil {
+		fields := append([]zap.Field{
+			zap.Uint64("query_id", q.id),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_delay", delay),
+			zap.Error(err),
+		}, q.fields...)
+
+		ce.Write(fields...)
+	}
+}
+
+func (q loggedQuery) QueryStats(stats zapcore.ObjectMarshaler) {
+	if ce := q.logger.log.Check(zap.InfoLevel, q.queryType); ce != nil {
+		fields := append([]zap.Field{
+			zap.Uint64("query_id", q.id),
+			zap.Object("stats", stats),
+		}, q.fields...)
+
+		ce.Write(fields...)
+	}
+}
+
+func (q loggedQuery) QueryCacheHit() {
+	q.logCache(true)
+}
+
+func (q loggedQuery) QueryCacheMiss() {
+	q.logCache(false)
+}
+
+func (q loggedQuery) QueryCacheFailed(err error) {
+	if ce := q.logger.log.Check(zap.WarnLevel, q.queryType); ce != nil {
+		fields := append([]zap.Field{
+			zap.Uint64("query_id", q.id),
+			zap.NamedError("cache_error", err),
+		}, q.fields...)
+
+		ce.Write(fields...)
+	}
+}
+
+func (q loggedQuery) logCache(hit bool) {
+	if ce := q.logger.log.Check(zap.InfoLevel, q.queryType); ce != nil {
+		fields := append([]zap.Field{
+			zap.Uint64("query_id", q.id),
+			zap.Bool("cache_hit", hit),
+		}, q.fields...)
+
+		ce.Write(fields...)
 	}
 }
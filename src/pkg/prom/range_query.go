@@ -4,10 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/url"
-	"strconv"
 	"time"
 
-	"github.com/mmihic/httplib/src/pkg/httplib"
 	"github.com/prometheus/common/model"
 	"go.uber.org/zap"
 )
@@ -18,21 +16,54 @@ type RangeQuery interface {
 	Start(t time.Time) RangeQuery
 	End(t time.Time) RangeQuery
 	Step(n model.Duration) RangeQuery
+
+	// SplitBy splits the query into sub-range queries whose boundaries are
+	// aligned to multiples of the given duration since the Unix epoch,
+	// running them in parallel and merging the results.
+	SplitBy(d time.Duration) RangeQuery
+
+	// AutoSplit splits the query only if it would return more points per
+	// series than Prometheus allows.
+	AutoSplit() RangeQuery
+
+	// MaxParallel limits the number of sub-range queries run in parallel
+	// when the query is split.
+	MaxParallel(n int) RangeQuery
+
+	// Timeout sets the server-side evaluation timeout. Defaults to the time
+	// remaining before the context deadline, if there is one.
+	Timeout(d time.Duration) RangeQuery
+
+	// WithStats requests query execution statistics, returned in
+	// Result.Stats.
+	WithStats() RangeQuery
+
+	// Stream runs the query, passing each series to fn as it is decoded
+	// rather than returning the whole matrix at once.
+	Stream(ctx context.Context, fn SeriesFunc) (*Result, error)
 }
 
+// defaultStep is the step of a RangeQuery if none is set.
+const defaultStep = model.Duration(time.Minute)
+
 func (c *client) RangeQuery(q string) RangeQuery {
 	return rangeQuery{
 		c:    c,
 		q:    q,
-		step: model.Duration(time.Minute),
+		step: defaultStep,
 	}
 }
 
 type rangeQuery struct {
-	c          *client
-	q          string
-	start, end time.Time
-	step       model.Duration
+	c           *client
+	q           string
+	start, end  time.Time
+	step        model.Duration
+	split       time.Duration
+	autoSplit   bool
+	maxParallel int
+	timeout     time.Duration
+	stats       bool
 }
 
 func (q rangeQuery) Start(t time.Time) RangeQuery {
@@ -50,13 +81,34 @@ func (q rangeQuery) Step(step model.Duration) RangeQuery {
 	return q
 }
 
-func (q rangeQuery) Do(ctx context.Context) (*Result, error) {
-	log := q.c.queryLog.BeginQuery("range-query",
-		zap.String("query", q.q),
-		zap.Time("start", q.start),
-		zap.Time("end", q.end),
-		zap.Duration("step", time.Duration(q.step)))
+func (q rangeQuery) SplitBy(d time.Duration) RangeQuery {
+	q.split = d
+	q.autoSplit = false
+	return q
+}
+
+func (q rangeQuery) AutoSplit() RangeQuery {
+	q.split = 0
+	q.autoSplit = true
+	return q
+}
+
+func (q rangeQuery) MaxParallel(n int) RangeQuery {
+	q.maxParallel = n
+	return q
+}
+
+func (q rangeQuery) Timeout(d time.Duration) RangeQuery {
+	q.timeout = d
+	return q
+}
+
+func (q rangeQuery) WithStats() RangeQuery {
+	q.stats = true
+	return q
+}
 
+func (q rangeQuery) Do(ctx context.Context) (*Result, error) {
 	if q.start.IsZero() {
 		return nil, fmt.Errorf("'start' must be set for range queries")
 	}
@@ -65,24 +117,47 @@ func (q rangeQuery) Do(ctx context.Context) (*Result, error) {
 		return nil, fmt.Errorf("'end' must be set for range queries")
 	}
 
+	if shards := q.shards(); len(shards) > 1 {
+		return q.doSplit(ctx, shards)
+	}
+
 	p := url.Values{}
 	p.Add("query", q.q)
-	p.Add("start", strconv.FormatInt(q.start.Unix(), 10))
-	p.Add("end", strconv.FormatInt(q.end.Unix(), 10))
+	p.Add("start", formatTime(q.start))
+	p.Add("end", formatTime(q.end))
 	p.Add("step", q.step.String())
 
+	timeout := queryTimeout(ctx, q.timeout)
+	if timeout > 0 {
+		p.Add("timeout", model.Duration(timeout).String())
+	}
+
+	if q.stats {
+		p.Add("stats", "all")
+	}
+
+	log := q.c.queryLog.BeginQuery("range-query",
+		zap.String("query", q.q),
+		zap.Time("start", q.start),
+		zap.Time("end", q.end),
+		zap.Duration("step", time.Duration(q.step)),
+		zap.Duration("timeout", timeout))
+
 	var r Result
-	if err := q.c.http.Post(ctx, pathRangeQuery,
-		httplib.FormURLEncoded(p), httplib.JSON(&r)); err != nil {
+	if err := q.c.post(ctx, log, pathRangeQuery, p, &r); err != nil {
 		log.QueryFailed(err)
+		return nil, err
+	}
 
-		if httperr, ok := httplib.UnwrapError(err); ok {
-			return nil, NewError(httperr.StatusCode, httperr.Body.String())
-		}
-
+	if err := r.Err(); err != nil {
+		log.QueryFailed(err)
 		return nil, err
 	}
 
+	if r.Stats != nil {
+		log.QueryStats(r.Stats)
+	}
+
 	log.QueryComplete(r)
 	return &r, nil
 }
@@ -0,0 +1,225 @@
+package prom
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// maxPointsPerSeries is the maximum number of points Prometheus will
+	// return for a single series in a range query.
+	maxPointsPerSeries = 11000
+
+	// autoSplitPoints is the number of points per series in each shard when
+	// automatically splitting a range query, leaving headroom below
+	// maxPointsPerSeries.
+	autoSplitPoints = 10000
+)
+
+var unixEpoch = time.Unix(0, 0)
+
+type timeRange struct {
+	start, end time.Time
+}
+
+// shards returns the time ranges the query should be split into, or nil
+// if the query should be issued as-is.
+func (q rangeQuery) shards() []timeRange {
+	var (
+		step  = time.Duration(q.step)
+		split = q.split
+	)
+
+	if q.autoSplit {
+		if step <= 0 || q.end.Sub(q.start)/step < maxPointsPerSeries {
+			return nil
+		}
+
+		split = step * autoSplitPoints
+	}
+
+	if split <= 0 || step <= 0 || !q.end.After(q.start) {
+		return nil
+	}
+
+	return splitRange(q.start, q.end, step, split)
+}
+
+// splitRange splits [start, end] into sub-ranges whose boundaries fall on
+// multiples of split since the Unix epoch, nudged forward onto the step
+// grid anchored at start so that every shard evaluates at the same
+// timestamps as the full query. Adjacent shards share their boundary
+// timestamp.
+func splitRange(start, end time.Time, step, split time.Duration) []timeRange {
+	var shards []timeRange
+	for shardStart := start; ; {
+		boundary := truncateSinceEpoch(shardStart, split).Add(split)
+
+		// Align the boundary onto the step grid
+		shardEnd := start.Add(((boundary.Sub(start) + step - 1) / step) * step)
+		if !shardEnd.Before(end) {
+			return append(shards, timeRange{start: shardStart, end: end})
+		}
+
+		shards = append(shards, timeRange{start: shardStart, end: shardEnd})
+		shardStart = shardEnd
+	}
+}
+
+// truncateSinceEpoch rounds t down to a multiple of d since the Unix
+// epoch. Unlike time.Truncate, which counts from the zero time, this puts
+// week boundaries on Thursdays as other Prometheus tooling does.
+func truncateSinceEpoch(t time.Time, d time.Duration) time.Time {
+	offset := t.Sub(unixEpoch) % d
+	if offset < 0 {
+		offset += d
+	}
+
+	return t.Add(-offset)
+}
+
+// doSplit runs the query as a set of sub-range queries in parallel and
+// stitches the results back together.
+func (q rangeQuery) doSplit(ctx context.Context, shards []timeRange) (*Result, error) {
+	var eg errgroup.Group
+
+	maxParallel := q.maxParallel
+	if maxParallel == 0 {
+		maxParallel = len(shards)
+	}
+
+	eg.SetLimit(maxParallel)
+
+	shardResults := make([]*Result, len(shards))
+	for i, shard := range shards {
+		var (
+			idxForResults = i
+			shardQuery    = q
+		)
+
+		shardQuery.start, shardQuery.end = shard.start, shard.end
+		shardQuery.split, shardQuery.autoSplit = 0, false
+
+		eg.Go(func() error {
+			r, err := shardQuery.Do(ctx)
+			if err != nil {
+				return err
+			}
+
+			shardResults[idxForResults] = r
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var (
+		matrices = make([]model.Matrix, 0, len(shardResults))
+		warnings []string
+		infos    []string
+		stats    = make([]*Stats, 0, len(shardResults))
+	)
+	for i, r := range shardResults {
+		m, ok := r.Data.(model.Matrix)
+		if !ok && r.Data != nil {
+			return nil, fmt.Errorf("unexpected result type %s for range query shard %s - %s",
+				r.Data.Type(), shards[i].start, shards[i].end)
+		}
+
+		matrices = append(matrices, m)
+		warnings = appendUnique(warnings, r.Warnings...)
+		infos = appendUnique(infos, r.Infos...)
+		stats = append(stats, r.Stats)
+	}
+
+	return &Result{
+		Status:   StatusSuccess,
+		Data:     mergeMatrices(matrices...),
+		Warnings: warnings,
+		Infos:    infos,
+		Stats:    mergeStats(stats...),
+	}, nil
+}
+
+// mergeMatrices merges a set of matrices into a single matrix with one
+// SampleStream per series. Samples within each series are sorted by
+// timestamp, and duplicate samples for the same timestamp are dropped.
+func mergeMatrices(matrices ...model.Matrix) model.Matrix {
+	var (
+		merged model.Matrix
+		byFP   = map[model.Fingerprint]*model.SampleStream{}
+	)
+
+	for _, m := range matrices {
+		for _, ss := range m {
+			fp := ss.Metric.Fingerprint()
+			existing, ok := byFP[fp]
+			if !ok {
+				existing = &model.SampleStream{Metric: ss.Metric}
+				byFP[fp] = existing
+				merged = append(merged, existing)
+			}
+
+			existing.Values = append(existing.Values, ss.Values...)
+			existing.Histograms = append(existing.Histograms, ss.Histograms...)
+		}
+	}
+
+	for _, ss := range merged {
+		ss.Values = dedupeByTimestamp(ss.Values, func(v model.SamplePair) model.Time {
+			return v.Timestamp
+		})
+
+		if len(ss.Histograms) != 0 {
+			ss.Histograms = dedupeByTimestamp(ss.Histograms, func(v model.SampleHistogramPair) model.Time {
+				return v.Timestamp
+			})
+		}
+	}
+
+	return merged
+}
+
+// dedupeByTimestamp sorts values by timestamp, keeping only the first
+// value for each timestamp.
+func dedupeByTimestamp[T any](values []T, ts func(T) model.Time) []T {
+	sort.SliceStable(values, func(i, j int) bool {
+		return ts(values[i]) < ts(values[j])
+	})
+
+	deduped := values[:0]
+	for _, v := range values {
+		if len(deduped) != 0 && ts(deduped[len(deduped)-1]) == ts(v) {
+			continue
+		}
+		deduped = append(deduped, v)
+	}
+
+	return deduped
+}
+
+// appendUnique appends the values not already present in the slice.
+func appendUnique(existing []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, e := range existing {
+			if e == v {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			existing = append(existing, v)
+		}
+	}
+
+	return existing
+}
@@ -0,0 +1,146 @@
+package prom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmihic/golib/src/pkg/timex"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRange(t *testing.T) {
+	var (
+		start = timex.MustParseTime(time.RFC3339, "2024-01-01T10:30:00Z")
+		end   = timex.MustParseTime(time.RFC3339, "2024-01-03T06:00:00Z")
+	)
+
+	shards := splitRange(start, end, 7*time.Minute, 24*time.Hour)
+	require.Len(t, shards, 3)
+
+	// Shard boundaries are the first step after midnight, and adjacent
+	// shards share their boundaries.
+	assert.Equal(t, start, shards[0].start)
+	assert.Equal(t, "2024-01-02T00:02:00Z", shards[0].end.Format(time.RFC3339))
+	assert.Equal(t, shards[0].end, shards[1].start)
+	assert.Equal(t, "2024-01-03T00:04:00Z", shards[1].end.Format(time.RFC3339))
+	assert.Equal(t, shards[1].end, shards[2].start)
+	assert.Equal(t, end, shards[2].end)
+
+	for _, shard := range shards {
+		assert.Zero(t, shard.start.Sub(start)%(7*time.Minute))
+	}
+}
+
+func TestSplitRange_Weeks(t *testing.T) {
+	var (
+		start = timex.MustParseTime(time.RFC3339, "2024-01-01T00:00:00Z")
+		end   = timex.MustParseTime(time.RFC3339, "2024-01-20T00:00:00Z")
+	)
+
+	// Weeks are counted from the Unix epoch, a Thursday, rather than from
+	// the zero time, a Monday
+	shards := splitRange(start, end, time.Hour, 7*24*time.Hour)
+	require.Len(t, shards, 4)
+	assert.Equal(t, "2024-01-04T00:00:00Z", shards[0].end.Format(time.RFC3339))
+	assert.Equal(t, "2024-01-11T00:00:00Z", shards[1].end.Format(time.RFC3339))
+	assert.Equal(t, "2024-01-18T00:00:00Z", shards[2].end.Format(time.RFC3339))
+	assert.Equal(t, end, shards[3].end)
+}
+
+func TestTruncateSinceEpoch(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	assert.Equal(t, time.Unix(0, 0), truncateSinceEpoch(time.Unix(3600, 0), week))
+	assert.Equal(t, time.Unix(-int64(week/time.Second), 0), truncateSinceEpoch(time.Unix(-3600, 0), week))
+}
+
+func TestRangeQuery_Shards(t *testing.T) {
+	var (
+		start = timex.MustParseTime(time.RFC3339, "2024-01-01T00:00:00Z")
+		q     = rangeQuery{
+			start: start,
+			end:   start.Add(24 * time.Hour),
+			step:  model.Duration(time.Minute),
+		}
+	)
+
+	// No splitting unless requested
+	assert.Nil(t, q.shards())
+
+	// Auto split does nothing if below the point limit
+	assert.Nil(t, q.AutoSplit().(rangeQuery).shards())
+
+	// Auto split splits if above the point limit
+	q.step = model.Duration(time.Second)
+	assert.Len(t, q.AutoSplit().(rangeQuery).shards(), 10)
+
+	// Explicit splits
+	assert.Len(t, q.SplitBy(time.Hour).(rangeQuery).shards(), 24)
+}
+
+func TestMergeMatrices(t *testing.T) {
+	var (
+		m1 = model.Matrix{
+			{
+				Metric: model.Metric{"cluster": "muster"},
+				Values: []model.SamplePair{
+					{Timestamp: 1000, Value: 1},
+					{Timestamp: 2000, Value: 2},
+				},
+			},
+			{
+				Metric: model.Metric{"cluster": "foosball"},
+				Values: []model.SamplePair{
+					{Timestamp: 2000, Value: 20},
+				},
+			},
+		}
+		m2 = model.Matrix{
+			{
+				Metric: model.Metric{"cluster": "foosball"},
+				Values: []model.SamplePair{
+					{Timestamp: 2000, Value: 20},
+					{Timestamp: 3000, Value: 30},
+				},
+			},
+			{
+				Metric: model.Metric{"cluster": "muster"},
+				Values: []model.SamplePair{
+					{Timestamp: 2000, Value: 2},
+					{Timestamp: 3000, Value: 3},
+				},
+			},
+			{
+				Metric: model.Metric{"cluster": "zed"},
+				Values: []model.SamplePair{
+					{Timestamp: 3000, Value: 300},
+				},
+			},
+		}
+	)
+
+	assert.Equal(t, model.Matrix{
+		{
+			Metric: model.Metric{"cluster": "muster"},
+			Values: []model.SamplePair{
+				{Timestamp: 1000, Value: 1},
+				{Timestamp: 2000, Value: 2},
+				{Timestamp: 3000, Value: 3},
+			},
+		},
+		{
+			Metric: model.Metric{"cluster": "foosball"},
+			Values: []model.SamplePair{
+				{Timestamp: 2000, Value: 20},
+				{Timestamp: 3000, Value: 30},
+			},
+		},
+		{
+			Metric: model.Metric{"cluster": "zed"},
+			Values: []model.SamplePair{
+				{Timestamp: 3000, Value: 300},
+			},
+		},
+	}, mergeMatrices(m1, m2))
+}
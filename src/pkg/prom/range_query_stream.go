@@ -0,0 +1,299 @@
+package prom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+// A SeriesFunc is called with an iterator over the samples of each series
+// returned by a streamed query.
+type SeriesFunc func(iter ValueIter) error
+
+// Stream runs the query, decoding the response one series at a time and
+// passing each to fn as it arrives rather than holding the full matrix in
+// memory. The returned Result carries the status, warnings, infos and
+// stats, but no Data.
+//
+// If the query is split, the shards are streamed one after another, so a
+// series spanning several shards is passed to fn once per shard.
+func (q rangeQuery) Stream(ctx context.Context, fn SeriesFunc) (*Result, error) {
+	if q.start.IsZero() {
+		return nil, fmt.Errorf("'start' must be set for range queries")
+	}
+
+	if q.end.IsZero() {
+		return nil, fmt.Errorf("'end' must be set for range queries")
+	}
+
+	shards := q.shards()
+	if len(shards) <= 1 {
+		return q.streamRange(ctx, fn, nil)
+	}
+
+	var results []*Result
+	for i, shard := range shards {
+		shardQuery := q
+		shardQuery.start, shardQuery.end = shard.start, shard.end
+
+		// Adjacent shards share their boundary timestamp, so drop it from
+		// all but the first
+		var skip *model.Time
+		if i > 0 {
+			boundary := model.TimeFromUnixNano(shard.start.UnixNano())
+			skip = &boundary
+		}
+
+		r, err := shardQuery.streamRange(ctx, fn, skip)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, r)
+	}
+
+	var (
+		warnings []string
+		infos    []string
+		stats    = make([]*Stats, 0, len(results))
+	)
+	for _, r := range results {
+		warnings = appendUnique(warnings, r.Warnings...)
+		infos = appendUnique(infos, r.Infos...)
+		stats = append(stats, r.Stats)
+	}
+
+	return &Result{
+		Status:   StatusSuccess,
+		Warnings: warnings,
+		Infos:    infos,
+		Stats:    mergeStats(stats...),
+	}, nil
+}
+
+// streamRange streams a single, unsplit range query, dropping samples at
+// the skip timestamp if set.
+func (q rangeQuery) streamRange(ctx context.Context, fn SeriesFunc, skip *model.Time) (*Result, error) {
+	p := url.Values{}
+	p.Add("query", q.q)
+	p.Add("start", formatTime(q.start))
+	p.Add("end", formatTime(q.end))
+	p.Add("step", q.step.String())
+
+	timeout := queryTimeout(ctx, q.timeout)
+	if timeout > 0 {
+		p.Add("timeout", model.Duration(timeout).String())
+	}
+
+	if q.stats {
+		p.Add("stats", "all")
+	}
+
+	log := q.c.queryLog.BeginQuery("range-query-stream",
+		zap.String("query", q.q),
+		zap.Time("start", q.start),
+		zap.Time("end", q.end),
+		zap.Duration("step", time.Duration(q.step)),
+		zap.Duration("timeout", timeout))
+
+	var r *Result
+	err := q.c.stream(ctx, log, pathRangeQuery, p, func(body io.Reader) error {
+		var err error
+		r, err = decodeMatrixStream(body, func(ss *model.SampleStream) error {
+			if skip != nil {
+				ss.Values = dropTimestamp(ss.Values, *skip, func(v model.SamplePair) model.Time {
+					return v.Timestamp
+				})
+				ss.Histograms = dropTimestamp(ss.Histograms, *skip, func(v model.SampleHistogramPair) model.Time {
+					return v.Timestamp
+				})
+			}
+
+			return fn(NewValueIter(model.Matrix{ss}))
+		})
+		return err
+	})
+	if err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if err := r.Err(); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if r.Stats != nil {
+		log.QueryStats(r.Stats)
+	}
+
+	log.QueryComplete(r)
+	return r, nil
+}
+
+// decodeMatrixStream decodes a query response, passing each series of a
+// matrix result to fn as it is decoded. The remainder of the response is
+// returned as a Result without Data. Errors from fn are returned as is.
+func decodeMatrixStream(r io.Reader, fn func(ss *model.SampleStream) error) (*Result, error) {
+	var (
+		dec    = json.NewDecoder(r)
+		result Result
+	)
+
+	err := decodeObject(dec, func(key string) error {
+		switch key {
+		case "status":
+			return dec.Decode(&result.Status)
+		case "errorType":
+			return dec.Decode(&result.ErrorType)
+		case "error":
+			return dec.Decode(&result.Error)
+		case "warnings":
+			return dec.Decode(&result.Warnings)
+		case "infos":
+			return dec.Decode(&result.Infos)
+		case "data":
+			return decodeMatrixData(dec, &result, fn)
+		default:
+			var ignored json.RawMessage
+			return dec.Decode(&ignored)
+		}
+	})
+	var seriesErr seriesFuncError
+	if errors.As(err, &seriesErr) {
+		return nil, seriesErr.err
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode streamed response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// seriesFuncError marks an error returned by the function passed to
+// decodeMatrixStream, so it is not reported as a decoding failure.
+type seriesFuncError struct {
+	err error
+}
+
+func (err seriesFuncError) Error() string {
+	return err.err.Error()
+}
+
+// decodeMatrixData decodes the data section of a response.
+func decodeMatrixData(dec *json.Decoder, result *Result, fn func(ss *model.SampleStream) error) error {
+	var resultType model.ValueType
+	return decodeObject(dec, func(key string) error {
+		switch key {
+		case "resultType":
+			if err := dec.Decode(&resultType); err != nil {
+				return err
+			}
+
+			if resultType != model.ValMatrix {
+				return fmt.Errorf("unexpected value type %q", resultType)
+			}
+
+			return nil
+
+		case "result":
+			if resultType != model.ValMatrix {
+				return fmt.Errorf("result before resultType %q", model.ValMatrix)
+			}
+
+			return decodeArray(dec, func() error {
+				var ss model.SampleStream
+				if err := dec.Decode(&ss); err != nil {
+					return err
+				}
+
+				if err := fn(&ss); err != nil {
+					return seriesFuncError{err: err}
+				}
+
+				return nil
+			})
+
+		case "stats":
+			return dec.Decode(&result.Stats)
+
+		default:
+			var ignored json.RawMessage
+			return dec.Decode(&ignored)
+		}
+	})
+}
+
+// decodeObject decodes a JSON object, calling fn to decode the value of
+// each key.
+func decodeObject(dec *json.Decoder, fn func(key string) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected object key, got %v", tok)
+		}
+
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+// decodeArray decodes a JSON array, calling fn to decode each element.
+func decodeArray(dec *json.Decoder, fn func() error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok != delim {
+		return fmt.Errorf("expected %s, got %v", delim, tok)
+	}
+
+	return nil
+}
+
+// dropTimestamp removes the values at the given timestamp.
+func dropTimestamp[T any](values []T, t model.Time, ts func(T) model.Time) []T {
+	kept := values[:0]
+	for _, v := range values {
+		if ts(v) != t {
+			kept = append(kept, v)
+		}
+	}
+
+	return kept
+}
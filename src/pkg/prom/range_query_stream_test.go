@@ -0,0 +1,181 @@
+package prom
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeQuery_Stream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, pathRangeQuery, r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("API-Token"))
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "up", r.PostForm.Get("query"))
+		assert.Equal(t, "all", r.PostForm.Get("stats"))
+
+		_, _ = w.Write([]byte(`{
+  "status": "success",
+  "warnings": ["something odd"],
+  "data": {
+    "resultType": "matrix",
+    "result": [
+      {"metric": {"job": "a"}, "values": [[1708028160, "1"], [1708028220, "2"]]},
+      {"metric": {"job": "b"}, "values": [[1708028160, "3"]]}
+    ],
+    "stats": {"samples": {"totalQueryableSamples": 3, "peakSamples": 3}}
+  }
+}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithHeader("API-Token", "secret"))
+	require.NoError(t, err)
+
+	var (
+		metrics []string
+		values  []string
+	)
+	r, err := c.RangeQuery("up").
+		Start(time.Unix(1708028160, 0)).
+		End(time.Unix(1708028220, 0)).
+		Step(model.Duration(time.Minute)).
+		WithStats().
+		Stream(context.TODO(), func(iter ValueIter) error {
+			for iter.Next() {
+				metrics = append(metrics, iter.Metric().String())
+				values = append(values, iter.StringValue())
+			}
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{job="a"}`, `{job="a"}`, `{job="b"}`}, metrics)
+	assert.Equal(t, []string{"1", "2", "3"}, values)
+	assert.Nil(t, r.Data)
+	assert.Equal(t, Warnings{"something odd"}, r.Warnings)
+	require.NotNil(t, r.Stats)
+	assert.Equal(t, int64(3), r.Stats.Samples.TotalQueryableSamples)
+}
+
+func TestRangeQuery_StreamSplit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		start, end := r.PostForm.Get("start"), r.PostForm.Get("end")
+		_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "matrix", "result": [
+  {"metric": {"job": "a"}, "values": [[` + start + `, "1"], [` + end + `, "2"]]}
+]}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	var timestamps []int64
+	_, err = c.RangeQuery("up").
+		Start(time.Unix(0, 0)).
+		End(time.Unix(7200, 0)).
+		Step(model.Duration(time.Minute)).
+		SplitBy(time.Hour).
+		Stream(context.TODO(), func(iter ValueIter) error {
+			for iter.Next() {
+				timestamps = append(timestamps, iter.Timestamp().Unix())
+			}
+			return nil
+		})
+	require.NoError(t, err)
+
+	// The shared boundary at 3600 is only passed once
+	assert.Equal(t, []int64{0, 3600, 7200}, timestamps)
+}
+
+func TestRangeQuery_StreamErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		status      int
+		body        string
+		expectedErr string
+	}{
+		{
+			"error envelope",
+			http.StatusOK,
+			`{"status": "error", "errorType": "execution", "error": "too many samples"}`,
+			"error_type=execution, msg=too many samples",
+		},
+		{
+			"unexpected result type",
+			http.StatusOK,
+			`{"status": "success", "data": {"resultType": "vector", "result": []}}`,
+			`unexpected value type "vector"`,
+		},
+		{
+			"truncated response",
+			http.StatusOK,
+			`{"status": "success", "data": {"resultType": "matrix", "result": [{"metric": {}`,
+			"unable to decode streamed response",
+		},
+		{
+			"http error",
+			http.StatusBadRequest,
+			`bad query`,
+			"status_code: 400",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c, err := NewClient(srv.URL)
+			require.NoError(t, err)
+
+			_, err = c.RangeQuery("up").
+				Start(time.Unix(0, 0)).
+				End(time.Unix(60, 0)).
+				Step(model.Duration(time.Minute)).
+				Stream(context.TODO(), func(iter ValueIter) error { return nil })
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestRangeQuery_StreamSeriesFuncError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "matrix", "result": [
+  {"metric": {"job": "api"}, "values": [[0, "1"]]},
+  {"metric": {"job": "db"}, "values": [[0, "1"]]}
+]}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	errStop := errors.New("stop")
+	_, err = c.RangeQuery("up").
+		Start(time.Unix(0, 0)).
+		End(time.Unix(60, 0)).
+		Step(model.Duration(time.Minute)).
+		Stream(context.TODO(), func(iter ValueIter) error { return errStop })
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, "stop", err.Error())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+
+	d := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, d > 50*time.Second && d <= time.Minute, d.String())
+}
@@ -0,0 +1,438 @@
+package prom
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"go.uber.org/zap"
+)
+
+const (
+	remoteReadVersion = "0.1.0"
+
+	contentTypeProtobuf         = "application/x-protobuf"
+	contentTypeStreamedProtobuf = "application/x-streamed-protobuf"
+
+	// maxRemoteReadFrameSize is the largest frame accepted in a streamed
+	// remote read response, matching the Prometheus server's default.
+	maxRemoteReadFrameSize = 50 * 1024 * 1024
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// A RemoteReadQuery reads raw samples through the remote read API rather
+// than evaluating PromQL, e.g. for bulk exports.
+type RemoteReadQuery interface {
+	// Start sets the start of the time range to read.
+	Start(t time.Time) RemoteReadQuery
+
+	// End sets the end of the time range to read.
+	End(t time.Time) RemoteReadQuery
+
+	// Sampled requests a single response of raw samples rather than
+	// streamed chunks, for backends that do not support streaming.
+	Sampled() RemoteReadQuery
+
+	// Do runs the query, returning the samples of each matching series.
+	Do(ctx context.Context) (ValueIter, error)
+}
+
+func (c *client) RemoteRead(matchers ...*labels.Matcher) RemoteReadQuery {
+	return remoteReadQuery{
+		c:        c,
+		matchers: matchers,
+	}
+}
+
+type remoteReadQuery struct {
+	c        *client
+	matchers []*labels.Matcher
+	start    time.Time
+	end      time.Time
+	sampled  bool
+}
+
+func (q remoteReadQuery) Start(t time.Time) RemoteReadQuery {
+	q.start = t
+	return q
+}
+
+func (q remoteReadQuery) End(t time.Time) RemoteReadQuery {
+	q.end = t
+	return q
+}
+
+func (q remoteReadQuery) Sampled() RemoteReadQuery {
+	q.sampled = true
+	return q
+}
+
+func (q remoteReadQuery) Do(ctx context.Context) (ValueIter, error) {
+	if len(q.matchers) == 0 {
+		return nil, errors.New("at least one matcher must be set for remote read queries")
+	}
+
+	if q.start.IsZero() {
+		return nil, fmt.Errorf("'start' must be set for remote read queries")
+	}
+
+	if q.end.IsZero() {
+		return nil, fmt.Errorf("'end' must be set for remote read queries")
+	}
+
+	req, err := q.request()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := proto.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode remote read request: %w", err)
+	}
+
+	var (
+		body = snappy.Encode(nil, b)
+		m    model.Matrix
+	)
+
+	log := q.c.queryLog.BeginQuery("remote-read",
+		zap.Stringers("matchers", q.matchers),
+		zap.Time("start", q.start),
+		zap.Time("end", q.end))
+
+	err = q.c.call(ctx, log, func() error {
+		resp, err := q.c.doRaw(ctx, http.MethodPost, pathRemoteRead, http.Header{
+			"Content-Type":                     {contentTypeProtobuf},
+			"Content-Encoding":                 {"snappy"},
+			"X-Prometheus-Remote-Read-Version": {remoteReadVersion},
+		}, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if mediaType == contentTypeStreamedProtobuf {
+			m, err = decodeChunkedResponse(resp.Body, timestamp(q.start), timestamp(q.end))
+		} else {
+			m, err = decodeSampledResponse(resp.Body)
+		}
+		return err
+	})
+	if err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	log.QueryComplete(m)
+	return NewValueIter(m), nil
+}
+
+// request builds the remote read request for the query.
+func (q remoteReadQuery) request() (*prompb.ReadRequest, error) {
+	matchers := make([]*prompb.LabelMatcher, 0, len(q.matchers))
+	for _, m := range q.matchers {
+		var matchType prompb.LabelMatcher_Type
+		switch m.Type {
+		case labels.MatchEqual:
+			matchType = prompb.LabelMatcher_EQ
+		case labels.MatchNotEqual:
+			matchType = prompb.LabelMatcher_NEQ
+		case labels.MatchRegexp:
+			matchType = prompb.LabelMatcher_RE
+		case labels.MatchNotRegexp:
+			matchType = prompb.LabelMatcher_NRE
+		default:
+			return nil, fmt.Errorf("invalid matcher type %s", m.Type)
+		}
+
+		matchers = append(matchers, &prompb.LabelMatcher{
+			Type:  matchType,
+			Name:  m.Name,
+			Value: m.Value,
+		})
+	}
+
+	responseTypes := []prompb.ReadRequest_ResponseType{prompb.ReadRequest_SAMPLES}
+	if !q.sampled {
+		responseTypes = []prompb.ReadRequest_ResponseType{
+			prompb.ReadRequest_STREAMED_XOR_CHUNKS,
+			prompb.ReadRequest_SAMPLES,
+		}
+	}
+
+	return &prompb.ReadRequest{
+		Queries: []*prompb.Query{
+			{
+				StartTimestampMs: timestamp(q.start),
+				EndTimestampMs:   timestamp(q.end),
+				Matchers:         matchers,
+			},
+		},
+		AcceptedResponseTypes: responseTypes,
+	}, nil
+}
+
+// decodeSampledResponse decodes a snappy-compressed ReadResponse.
+func decodeSampledResponse(r io.Reader) (model.Matrix, error) {
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress remote read response: %w", err)
+	}
+
+	var resp prompb.ReadResponse
+	if err := proto.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("unable to decode remote read response: %w", err)
+	}
+
+	var m model.Matrix
+	for _, result := range resp.Results {
+		for _, ts := range result.Timeseries {
+			ss := &model.SampleStream{Metric: metricFromLabels(ts.Labels)}
+			for _, s := range ts.Samples {
+				ss.Values = append(ss.Values, model.SamplePair{
+					Timestamp: model.Time(s.Timestamp),
+					Value:     model.SampleValue(s.Value),
+				})
+			}
+
+			for _, h := range ts.Histograms {
+				ss.Histograms = append(ss.Histograms, model.SampleHistogramPair{
+					Timestamp: model.Time(h.Timestamp),
+					Histogram: sampleHistogram(histogramFromProto(h)),
+				})
+			}
+
+			m = append(m, ss)
+		}
+	}
+
+	return m, nil
+}
+
+// decodeChunkedResponse decodes a stream of ChunkedReadResponse frames,
+// keeping only the samples between mint and maxt. Each frame is a uvarint
+// length, followed by a big-endian CRC32 (Castagnoli) of the data, and
+// then the data itself.
+func decodeChunkedResponse(r io.Reader, mint, maxt int64) (model.Matrix, error) {
+	var (
+		br   = bufio.NewReader(r)
+		m    model.Matrix
+		last *model.SampleStream
+	)
+
+	for {
+		size, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) {
+			return m, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to read remote read frame: %w", err)
+		}
+
+		if size > maxRemoteReadFrameSize {
+			return nil, fmt.Errorf("remote read frame of %d bytes exceeds the limit of %d bytes",
+				size, maxRemoteReadFrameSize)
+		}
+
+		var checksum uint32
+		if err := binary.Read(br, binary.BigEndian, &checksum); err != nil {
+			return nil, fmt.Errorf("unable to read remote read frame: %w", unexpectedEOF(err))
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, fmt.Errorf("unable to read remote read frame: %w", unexpectedEOF(err))
+		}
+
+		if crc32.Checksum(data, castagnoliTable) != checksum {
+			return nil, errors.New("corrupt remote read frame: checksum mismatch")
+		}
+
+		var resp prompb.ChunkedReadResponse
+		if err := proto.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("unable to decode remote read frame: %w", err)
+		}
+
+		for _, series := range resp.ChunkedSeries {
+			metric := metricFromLabels(series.Labels)
+
+			// Series with many chunks are split across consecutive frames
+			ss := last
+			if ss == nil || !ss.Metric.Equal(metric) {
+				ss = &model.SampleStream{Metric: metric}
+				m = append(m, ss)
+				last = ss
+			}
+
+			for _, chk := range series.Chunks {
+				if err := appendChunk(ss, chk, mint, maxt); err != nil {
+					return nil, fmt.Errorf("series %s: %w", metric, err)
+				}
+			}
+		}
+	}
+}
+
+// appendChunk appends the samples of an encoded chunk between mint and
+// maxt to a series.
+func appendChunk(ss *model.SampleStream, chk prompb.Chunk, mint, maxt int64) error {
+	c, err := chunkenc.FromData(chunkenc.Encoding(chk.Type), chk.Data)
+	if err != nil {
+		return err
+	}
+
+	it := c.Iterator(nil)
+	for vt := it.Next(); vt != chunkenc.ValNone; vt = it.Next() {
+		if t := it.AtT(); t < mint || t > maxt {
+			continue
+		}
+
+		switch vt {
+		case chunkenc.ValFloat:
+			t, v := it.At()
+			ss.Values = append(ss.Values, model.SamplePair{
+				Timestamp: model.Time(t),
+				Value:     model.SampleValue(v),
+			})
+		case chunkenc.ValHistogram, chunkenc.ValFloatHistogram:
+			t, h := it.AtFloatHistogram(nil)
+			ss.Histograms = append(ss.Histograms, model.SampleHistogramPair{
+				Timestamp: model.Time(t),
+				Histogram: sampleHistogram(h),
+			})
+		}
+	}
+
+	return it.Err()
+}
+
+// metricFromLabels converts remote read labels into a metric.
+func metricFromLabels(ls []prompb.Label) model.Metric {
+	metric := make(model.Metric, len(ls))
+	for _, l := range ls {
+		metric[model.LabelName(l.Name)] = model.LabelValue(l.Value)
+	}
+
+	return metric
+}
+
+// histogramFromProto converts a remote read histogram, which holds either
+// integer deltas or float counts, into a float histogram.
+func histogramFromProto(hp prompb.Histogram) *histogram.FloatHistogram {
+	if hp.IsFloatHistogram() {
+		return &histogram.FloatHistogram{
+			Schema:          hp.Schema,
+			ZeroThreshold:   hp.ZeroThreshold,
+			ZeroCount:       hp.GetZeroCountFloat(),
+			Count:           hp.GetCountFloat(),
+			Sum:             hp.Sum,
+			PositiveSpans:   spansFromProto(hp.PositiveSpans),
+			PositiveBuckets: hp.PositiveCounts,
+			NegativeSpans:   spansFromProto(hp.NegativeSpans),
+			NegativeBuckets: hp.NegativeCounts,
+		}
+	}
+
+	h := &histogram.Histogram{
+		Schema:          hp.Schema,
+		ZeroThreshold:   hp.ZeroThreshold,
+		ZeroCount:       hp.GetZeroCountInt(),
+		Count:           hp.GetCountInt(),
+		Sum:             hp.Sum,
+		PositiveSpans:   spansFromProto(hp.PositiveSpans),
+		PositiveBuckets: hp.PositiveDeltas,
+		NegativeSpans:   spansFromProto(hp.NegativeSpans),
+		NegativeBuckets: hp.NegativeDeltas,
+	}
+
+	return h.ToFloat(nil)
+}
+
+func spansFromProto(spans []prompb.BucketSpan) []histogram.Span {
+	converted := make([]histogram.Span, 0, len(spans))
+	for _, s := range spans {
+		converted = append(converted, histogram.Span{Offset: s.Offset, Length: s.Length})
+	}
+
+	return converted
+}
+
+// sampleHistogram converts a float histogram into the bucketed form
+// returned by the query API, in ascending order and omitting empty buckets
+// as the API does.
+func sampleHistogram(h *histogram.FloatHistogram) *model.SampleHistogram {
+	sh := &model.SampleHistogram{
+		Count: model.FloatString(h.Count),
+		Sum:   model.FloatString(h.Sum),
+	}
+
+	for it := h.AllBucketIterator(); it.Next(); {
+		b := it.At()
+		if b.Count == 0 {
+			continue
+		}
+
+		sh.Buckets = append(sh.Buckets, &model.HistogramBucket{
+			Boundaries: bucketBoundaries(b),
+			Lower:      model.FloatString(b.Lower),
+			Upper:      model.FloatString(b.Upper),
+			Count:      model.FloatString(b.Count),
+		})
+	}
+
+	return sh
+}
+
+// bucketBoundaries returns the query API's encoding of which ends of a
+// bucket are inclusive.
+func bucketBoundaries(b histogram.Bucket[float64]) int32 {
+	switch {
+	case b.LowerInclusive && b.UpperInclusive:
+		return 3
+	case b.LowerInclusive:
+		return 1
+	case b.UpperInclusive:
+		return 0
+	default:
+		return 2
+	}
+}
+
+// timestamp converts a time into milliseconds since the epoch.
+func timestamp(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
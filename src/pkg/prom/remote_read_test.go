@@ -0,0 +1,221 @@
+package prom
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteRead_Sampled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := requireReadRequest(t, r)
+		assert.Equal(t, []prompb.ReadRequest_ResponseType{prompb.ReadRequest_SAMPLES}, req.AcceptedResponseTypes)
+
+		b, err := proto.Marshal(&prompb.ReadResponse{
+			Results: []*prompb.QueryResult{
+				{
+					Timeseries: []*prompb.TimeSeries{
+						{
+							Labels: []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "api"}},
+							Samples: []prompb.Sample{
+								{Timestamp: 1000, Value: 1},
+								{Timestamp: 2000, Value: 0},
+							},
+						},
+						{
+							Labels: []prompb.Label{{Name: "__name__", Value: "latency"}},
+							Histograms: []prompb.Histogram{
+								{
+									Timestamp:      3000,
+									Count:          &prompb.Histogram_CountInt{CountInt: 3},
+									Sum:            4.5,
+									Schema:         0,
+									ZeroCount:      &prompb.Histogram_ZeroCountInt{ZeroCountInt: 0},
+									PositiveSpans:  []prompb.BucketSpan{{Offset: 0, Length: 2}},
+									PositiveDeltas: []int64{1, 1},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", contentTypeProtobuf)
+		w.Header().Set("Content-Encoding", "snappy")
+		_, _ = w.Write(snappy.Encode(nil, b))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	iter, err := c.RemoteRead(labels.MustNewMatcher(labels.MatchEqual, "__name__", "up")).
+		Start(time.Unix(0, 0)).
+		End(time.Unix(10, 0)).
+		Sampled().
+		Do(context.TODO())
+	require.NoError(t, err)
+
+	require.True(t, iter.Next())
+	assert.Equal(t, model.Metric{"__name__": "up", "job": "api"}, iter.Metric())
+	assert.Equal(t, time.Unix(1, 0), iter.Timestamp())
+	assert.Equal(t, float64(1), iter.FloatValue())
+
+	require.True(t, iter.Next())
+	assert.Equal(t, float64(0), iter.FloatValue())
+
+	require.True(t, iter.Next())
+	require.True(t, iter.IsHistogram())
+	assert.Equal(t, &model.SampleHistogram{
+		Count: 3,
+		Sum:   4.5,
+		Buckets: model.HistogramBuckets{
+			{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 1},
+			{Boundaries: 0, Lower: 1, Upper: 2, Count: 2},
+		},
+	}, iter.HistogramValue())
+
+	assert.False(t, iter.Next())
+}
+
+func TestRemoteRead_Streamed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := requireReadRequest(t, r)
+		require.Len(t, req.Queries, 1)
+		assert.Equal(t, int64(1000), req.Queries[0].StartTimestampMs)
+		assert.Equal(t, int64(4000), req.Queries[0].EndTimestampMs)
+		assert.Equal(t, []*prompb.LabelMatcher{
+			{Type: prompb.LabelMatcher_RE, Name: "job", Value: "api.*"},
+		}, req.Queries[0].Matchers)
+		assert.Equal(t, prompb.ReadRequest_STREAMED_XOR_CHUNKS, req.AcceptedResponseTypes[0])
+
+		series := []prompb.Label{{Name: "job", Value: "api"}}
+		w.Header().Set("Content-Type", contentTypeStreamedProtobuf+"; proto=prometheus.ChunkedReadResponse")
+
+		// The series is split across two frames, and the first chunk
+		// includes a sample before the start of the range
+		writeFrame(t, w, &prompb.ChunkedReadResponse{
+			ChunkedSeries: []*prompb.ChunkedSeries{
+				{Labels: series, Chunks: []prompb.Chunk{xorChunk(t, 0, 1000, 2000)}},
+			},
+		})
+		writeFrame(t, w, &prompb.ChunkedReadResponse{
+			ChunkedSeries: []*prompb.ChunkedSeries{
+				{Labels: series, Chunks: []prompb.Chunk{xorChunk(t, 3000)}},
+				{Labels: []prompb.Label{{Name: "job", Value: "api-2"}}, Chunks: []prompb.Chunk{xorChunk(t, 4000)}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	iter, err := c.RemoteRead(labels.MustNewMatcher(labels.MatchRegexp, "job", "api.*")).
+		Start(time.Unix(1, 0)).
+		End(time.Unix(4, 0)).
+		Do(context.TODO())
+	require.NoError(t, err)
+
+	var (
+		metrics    []string
+		timestamps []int64
+	)
+	for iter.Next() {
+		metrics = append(metrics, iter.Metric().String())
+		timestamps = append(timestamps, iter.Timestamp().Unix())
+	}
+
+	assert.Equal(t, []string{`{job="api"}`, `{job="api"}`, `{job="api"}`, `{job="api-2"}`}, metrics)
+	assert.Equal(t, []int64{1, 2, 3, 4}, timestamps)
+}
+
+func TestRemoteRead_CorruptFrame(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentTypeStreamedProtobuf)
+
+		var buf bytes.Buffer
+		writeFrame(t, &buf, &prompb.ChunkedReadResponse{
+			ChunkedSeries: []*prompb.ChunkedSeries{
+				{Labels: []prompb.Label{{Name: "job", Value: "api"}}},
+			},
+		})
+
+		b := buf.Bytes()
+		b[len(b)-1] ^= 0xff
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	_, err = c.RemoteRead(labels.MustNewMatcher(labels.MatchEqual, "job", "api")).
+		Start(time.Unix(1, 0)).
+		End(time.Unix(4, 0)).
+		Do(context.TODO())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func requireReadRequest(t *testing.T, r *http.Request) *prompb.ReadRequest {
+	assert.Equal(t, pathRemoteRead, r.URL.Path)
+	assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+	assert.Equal(t, remoteReadVersion, r.Header.Get("X-Prometheus-Remote-Read-Version"))
+
+	compressed, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+
+	b, err := snappy.Decode(nil, compressed)
+	require.NoError(t, err)
+
+	var req prompb.ReadRequest
+	require.NoError(t, proto.Unmarshal(b, &req))
+	return &req
+}
+
+func xorChunk(t *testing.T, timestamps ...int64) prompb.Chunk {
+	chk := chunkenc.NewXORChunk()
+	app, err := chk.Appender()
+	require.NoError(t, err)
+
+	for _, ts := range timestamps {
+		app.Append(ts, float64(ts)/1000)
+	}
+
+	return prompb.Chunk{
+		MinTimeMs: timestamps[0],
+		MaxTimeMs: timestamps[len(timestamps)-1],
+		Type:      prompb.Chunk_XOR,
+		Data:      chk.Bytes(),
+	}
+}
+
+func writeFrame(t *testing.T, w io.Writer, resp *prompb.ChunkedReadResponse) {
+	b, err := proto.Marshal(resp)
+	require.NoError(t, err)
+
+	var header [binary.MaxVarintLen64 + 4]byte
+	n := binary.PutUvarint(header[:], uint64(len(b)))
+	binary.BigEndian.PutUint32(header[n:], crc32.Checksum(b, castagnoliTable))
+
+	_, err = w.Write(append(header[:n+4], b...))
+	require.NoError(t, err)
+}
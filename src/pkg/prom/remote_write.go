@@ -0,0 +1,235 @@
+package prom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	remoteWriteVersion = "0.1.0"
+
+	// DefaultRemoteWriteBatchSize is the default maximum number of samples
+	// sent in a single remote write request.
+	DefaultRemoteWriteBatchSize = 2000
+
+	// DefaultRemoteWriteShards is the default number of shards that write
+	// in parallel.
+	DefaultRemoteWriteShards = 4
+)
+
+// A RemoteWriter pushes series into a Prometheus-compatible store through
+// the remote write API, e.g. to backfill rollups computed by a
+// MonthlyQuery.
+//
+// Series are sharded by their labels, with each shard sending its series
+// in order and in batches, so that the samples of a series always arrive
+// in time order. Shards write in parallel. Requests that fail with a 5xx
+// or 429 response are retried according to the client's RetryPolicy.
+type RemoteWriter interface {
+	// BatchSize sets the maximum number of samples sent in each request.
+	BatchSize(n int) RemoteWriter
+
+	// Shards sets the number of shards writing in parallel.
+	Shards(n int) RemoteWriter
+
+	// Write writes the samples of a matrix or vector, returning once they
+	// have all been accepted. Native histograms are not supported, since
+	// the query API does not return them in a form that can be written.
+	Write(ctx context.Context, v model.Value) error
+}
+
+func (c *client) RemoteWriter() RemoteWriter {
+	return remoteWriter{
+		c:         c,
+		batchSize: DefaultRemoteWriteBatchSize,
+		shards:    DefaultRemoteWriteShards,
+	}
+}
+
+type remoteWriter struct {
+	c         *client
+	batchSize int
+	shards    int
+}
+
+func (w remoteWriter) BatchSize(n int) RemoteWriter {
+	w.batchSize = n
+	return w
+}
+
+func (w remoteWriter) Shards(n int) RemoteWriter {
+	w.shards = n
+	return w
+}
+
+func (w remoteWriter) Write(ctx context.Context, v model.Value) error {
+	if w.batchSize <= 0 {
+		return fmt.Errorf("invalid batch size %d", w.batchSize)
+	}
+
+	if w.shards <= 0 {
+		return fmt.Errorf("invalid number of shards %d", w.shards)
+	}
+
+	m, err := asMatrix(v)
+	if err != nil {
+		return err
+	}
+
+	shards := make([][]prompb.TimeSeries, w.shards)
+	for _, ss := range m {
+		if len(ss.Histograms) != 0 {
+			return fmt.Errorf("series %s: native histograms cannot be written", ss.Metric)
+		}
+
+		if len(ss.Values) == 0 {
+			continue
+		}
+
+		shard := uint64(ss.Metric.Fingerprint()) % uint64(w.shards)
+		shards[shard] = append(shards[shard], timeSeries(ss))
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, series := range shards {
+		series := series
+		if len(series) == 0 {
+			continue
+		}
+
+		eg.Go(func() error {
+			for _, batch := range batchSeries(series, w.batchSize) {
+				if err := w.send(ctx, batch); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return eg.Wait()
+}
+
+// send sends a single remote write request.
+func (w remoteWriter) send(ctx context.Context, series []prompb.TimeSeries) error {
+	b, err := proto.Marshal(&prompb.WriteRequest{Timeseries: series})
+	if err != nil {
+		return fmt.Errorf("unable to encode remote write request: %w", err)
+	}
+
+	var (
+		body    = snappy.Encode(nil, b)
+		samples = 0
+	)
+	for _, ts := range series {
+		samples += len(ts.Samples)
+	}
+
+	log := w.c.queryLog.BeginQuery("remote-write",
+		zap.Int("series", len(series)),
+		zap.Int("samples", samples))
+
+	err = w.c.callRetrying(ctx, log, isRemoteWriteRetryable, func() error {
+		resp, err := w.c.doRaw(ctx, http.MethodPost, pathRemoteWrite, http.Header{
+			"Content-Type":                      {contentTypeProtobuf},
+			"Content-Encoding":                  {"snappy"},
+			"X-Prometheus-Remote-Write-Version": {remoteWriteVersion},
+		}, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	})
+	if err != nil {
+		log.QueryFailed(err)
+		return err
+	}
+
+	log.QueryComplete(nil)
+	return nil
+}
+
+// isRemoteWriteRetryable returns true if a remote write request that failed
+// with err may succeed if retried. Following the remote write protocol, this
+// includes every 5xx response, since the samples were not accepted, and
+// not just the failures that are retryable for queries.
+func isRemoteWriteRetryable(err error) bool {
+	var promErr Error
+	if errors.As(err, &promErr) {
+		return promErr.Retryable() || promErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+// timeSeries converts a series into its remote write form, with labels
+// sorted by name and samples sorted by time as the protocol requires.
+func timeSeries(ss *model.SampleStream) prompb.TimeSeries {
+	ts := prompb.TimeSeries{
+		Labels:  make([]prompb.Label, 0, len(ss.Metric)),
+		Samples: make([]prompb.Sample, 0, len(ss.Values)),
+	}
+
+	for name, value := range ss.Metric {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: string(name), Value: string(value)})
+	}
+
+	sort.Slice(ts.Labels, func(i, j int) bool {
+		return ts.Labels[i].Name < ts.Labels[j].Name
+	})
+
+	for _, v := range ss.Values {
+		ts.Samples = append(ts.Samples, prompb.Sample{Timestamp: int64(v.Timestamp), Value: float64(v.Value)})
+	}
+
+	sort.SliceStable(ts.Samples, func(i, j int) bool {
+		return ts.Samples[i].Timestamp < ts.Samples[j].Timestamp
+	})
+
+	return ts
+}
+
+// batchSeries groups series into batches of at most batchSize samples,
+// splitting series that are too large for a single batch.
+func batchSeries(series []prompb.TimeSeries, batchSize int) [][]prompb.TimeSeries {
+	var (
+		batches [][]prompb.TimeSeries
+		batch   []prompb.TimeSeries
+		n       int
+	)
+
+	for _, ts := range series {
+		for samples := ts.Samples; len(samples) != 0; {
+			take := batchSize - n
+			if take > len(samples) {
+				take = len(samples)
+			}
+
+			batch = append(batch, prompb.TimeSeries{Labels: ts.Labels, Samples: samples[:take]})
+			samples, n = samples[take:], n+take
+
+			if n == batchSize {
+				batches, batch, n = append(batches, batch), nil, 0
+			}
+		}
+	}
+
+	if len(batch) != 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
@@ -0,0 +1,160 @@
+package prom
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteWriter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []*prompb.WriteRequest
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, pathRemoteWrite, r.URL.Path)
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, remoteWriteVersion, r.Header.Get("X-Prometheus-Remote-Write-Version"))
+
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		b, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+
+		var req prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(b, &req))
+
+		mu.Lock()
+		requests = append(requests, &req)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	err = c.RemoteWriter().
+		BatchSize(2).
+		Shards(1).
+		Write(context.TODO(), model.Matrix{
+			&model.SampleStream{
+				Metric: model.Metric{"job": "api", "__name__": "rollup"},
+				Values: []model.SamplePair{
+					{Timestamp: 3000, Value: 3},
+					{Timestamp: 1000, Value: 1},
+					{Timestamp: 2000, Value: 2},
+				},
+			},
+			&model.SampleStream{
+				Metric: model.Metric{"__name__": "rollup", "job": "db"},
+				Values: []model.SamplePair{{Timestamp: 1000, Value: 10}},
+			},
+		})
+	require.NoError(t, err)
+
+	// Samples are sorted by time and split into batches of two, keeping
+	// labels sorted by name
+	api := []prompb.Label{{Name: "__name__", Value: "rollup"}, {Name: "job", Value: "api"}}
+	db := []prompb.Label{{Name: "__name__", Value: "rollup"}, {Name: "job", Value: "db"}}
+	require.Len(t, requests, 2)
+	assert.Equal(t, []prompb.TimeSeries{
+		{Labels: api, Samples: []prompb.Sample{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 2}}},
+	}, requests[0].Timeseries)
+	assert.Equal(t, []prompb.TimeSeries{
+		{Labels: api, Samples: []prompb.Sample{{Timestamp: 3000, Value: 3}}},
+		{Labels: db, Samples: []prompb.Sample{{Timestamp: 1000, Value: 10}}},
+	}, requests[1].Timeseries)
+}
+
+func TestRemoteWriter_Retries(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Unlike queries, remote writes are retried on any 5xx response
+		switch attempts.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			http.Error(w, "ingester unhealthy", http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}))
+	require.NoError(t, err)
+
+	err = c.RemoteWriter().Write(context.TODO(), model.Vector{
+		&model.Sample{Metric: model.Metric{"__name__": "up"}, Timestamp: 1000, Value: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestRemoteWriter_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "out of order sample", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	err = c.RemoteWriter().Write(context.TODO(), model.Vector{
+		&model.Sample{Metric: model.Metric{"__name__": "up"}, Timestamp: 1000, Value: 1},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "out of order sample")
+
+	err = c.RemoteWriter().Write(context.TODO(), model.Vector{
+		&model.Sample{Metric: model.Metric{"__name__": "latency"}, Histogram: &model.SampleHistogram{Count: 1}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "native histograms cannot be written")
+}
+
+func TestBatchSeries(t *testing.T) {
+	samples := func(n int) []prompb.Sample {
+		s := make([]prompb.Sample, n)
+		for i := range s {
+			s[i].Timestamp = int64(i)
+		}
+		return s
+	}
+
+	batches := batchSeries([]prompb.TimeSeries{
+		{Samples: samples(5)},
+		{Samples: samples(1)},
+		{Samples: samples(2)},
+	}, 3)
+
+	var sizes [][]int
+	for _, batch := range batches {
+		var sz []int
+		for _, ts := range batch {
+			sz = append(sz, len(ts.Samples))
+		}
+		sizes = append(sizes, sz)
+	}
+
+	assert.Equal(t, [][]int{{3}, {2, 1}, {2}}, sizes)
+}
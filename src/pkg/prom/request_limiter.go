@@ -0,0 +1,77 @@
+package prom
+
+import (
+	"context"
+
+	"go.uber.org/atomic"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit limits the rate of requests issued by the client across
+// all query types to qps requests per second, allowing bursts of up to
+// burst requests.
+func WithRateLimit(qps float64, burst int) ClientOpt {
+	return func(c *client) {
+		c.limiter.rate = rate.NewLimiter(rate.Limit(qps), burst)
+	}
+}
+
+// WithMaxInFlight limits the number of requests the client will have
+// outstanding at any one time across all query types. Values less than 1
+// remove the limit.
+func WithMaxInFlight(n int) ClientOpt {
+	return func(c *client) {
+		if n < 1 {
+			c.limiter.inFlight = nil
+			return
+		}
+
+		c.limiter.inFlight = semaphore.NewWeighted(int64(n))
+	}
+}
+
+// A requestLimiter caps the rate and concurrency of requests. Requests
+// that are blocked waiting for capacity are counted in the queue depth.
+type requestLimiter struct {
+	rate     *rate.Limiter
+	inFlight *semaphore.Weighted
+	queued   atomic.Int64
+}
+
+// acquire blocks until the request is allowed to proceed or the context
+// is done. The returned func must be called once the request completes.
+func (l *requestLimiter) acquire(ctx context.Context) (func(), error) {
+	if l.rate == nil && l.inFlight == nil {
+		return func() {}, nil
+	}
+
+	l.queued.Inc()
+	defer l.queued.Dec()
+
+	if l.inFlight != nil {
+		if err := l.inFlight.Acquire(ctx, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	release := func() {
+		if l.inFlight != nil {
+			l.inFlight.Release(1)
+		}
+	}
+
+	if l.rate != nil {
+		if err := l.rate.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// queueDepth returns the number of requests waiting for capacity.
+func (l *requestLimiter) queueDepth() int {
+	return int(l.queued.Load())
+}
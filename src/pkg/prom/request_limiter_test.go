@@ -0,0 +1,87 @@
+package prom
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLimiter_Unlimited(t *testing.T) {
+	var c client
+	release, err := c.limiter.acquire(context.TODO())
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, 0, c.QueueDepth())
+}
+
+func TestRequestLimiter_MaxInFlight(t *testing.T) {
+	var c client
+	WithMaxInFlight(1)(&c)
+
+	release, err := c.limiter.acquire(context.TODO())
+	require.NoError(t, err)
+
+	acquired := make(chan struct{})
+	go func() {
+		release, err := c.limiter.acquire(context.TODO())
+		if assert.NoError(t, err) {
+			release()
+		}
+		close(acquired)
+	}()
+
+	require.Eventually(t, func() bool { return c.QueueDepth() == 1 },
+		time.Second, time.Millisecond)
+
+	release()
+	<-acquired
+	assert.Equal(t, 0, c.QueueDepth())
+}
+
+func TestRequestLimiter_MaxInFlightZeroIsUnlimited(t *testing.T) {
+	var c client
+	WithMaxInFlight(0)(&c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		_, err := c.limiter.acquire(ctx)
+		require.NoError(t, err)
+	}
+}
+
+func TestRequestLimiter_RespectsDeadline(t *testing.T) {
+	var c client
+	WithMaxInFlight(1)(&c)
+
+	release, err := c.limiter.acquire(context.TODO())
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = c.limiter.acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, c.QueueDepth())
+}
+
+func TestRequestLimiter_RateLimit(t *testing.T) {
+	var c client
+	WithRateLimit(1, 1)(&c)
+
+	release, err := c.limiter.acquire(context.TODO())
+	require.NoError(t, err)
+	release()
+
+	// The next token is a second away, which is past the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = c.limiter.acquire(ctx)
+	assert.Error(t, err)
+}
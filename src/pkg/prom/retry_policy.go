@@ -0,0 +1,104 @@
+package prom
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/mmihic/promlib/src/pkg/prom/querylog"
+)
+
+// A RetryPolicy controls how queries that fail with a retryable error
+// are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts, including delays
+	// requested by the server with Retry-After. Zero leaves them uncapped.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor by which the delay grows after each attempt.
+	Multiplier float64
+
+	// Jitter is the fraction (0-1) of each delay that is randomized.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with reasonable defaults.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// WithRetryPolicy sets the policy for retrying failed queries.
+func WithRetryPolicy(policy RetryPolicy) ClientOpt {
+	return func(c *client) {
+		c.retryPolicy = policy
+	}
+}
+
+// do calls fn until it succeeds, fails with an error that is not
+// retryable, or the maximum number of attempts is reached.
+func (policy RetryPolicy) do(
+	ctx context.Context, log querylog.LoggedQuery, retryable func(error) bool, fn func() error,
+) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= policy.MaxAttempts || !retryable(err) {
+			return err
+		}
+
+		delay := policy.backoff(attempt, err)
+		log.QueryRetrying(attempt, delay, err)
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+// backoff returns the delay to wait after the given attempt, honoring
+// any delay requested by the server up to MaxBackoff.
+func (policy RetryPolicy) backoff(attempt int, err error) time.Duration {
+	var promErr Error
+	if errors.As(err, &promErr) && promErr.RetryAfter > 0 {
+		if policy.MaxBackoff > 0 && promErr.RetryAfter > policy.MaxBackoff {
+			return policy.MaxBackoff
+		}
+
+		return promErr.RetryAfter
+	}
+
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxBackoff > 0 && delay > float64(policy.MaxBackoff) {
+		delay = float64(policy.MaxBackoff)
+	}
+
+	if policy.Jitter > 0 {
+		jitter := math.Min(policy.Jitter, 1)
+		delay = delay * (1 - jitter + (rand.Float64() * jitter))
+	}
+
+	return time.Duration(delay)
+}
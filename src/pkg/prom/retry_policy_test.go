@@ -0,0 +1,188 @@
+package prom
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/mmihic/promlib/src/pkg/prom/querylog"
+)
+
+func TestRetryPolicy_RetriesRetryableErrors(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Multiplier:     2,
+	}
+
+	var attempts int
+	err := policy.do(context.TODO(), querylog.NewNop().BeginQuery("test"), IsRetryable, func() error {
+		attempts++
+		if attempts < 3 {
+			return NewError(http.StatusServiceUnavailable, "unavailable")
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryPolicy_GivesUpAfterMaxAttempts(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+	}
+
+	var attempts int
+	err := policy.do(context.TODO(), querylog.NewNop().BeginQuery("test"), IsRetryable, func() error {
+		attempts++
+		return NewError(http.StatusTooManyRequests, "slow down")
+	})
+
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetryPolicy_DoesNotRetryPermanentErrors(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+	}
+
+	var attempts int
+	err := policy.do(context.TODO(), querylog.NewNop().BeginQuery("test"), IsRetryable, func() error {
+		attempts++
+		return NewError(http.StatusBadRequest, "bad query")
+	})
+
+	assert.ErrorIs(t, err, ErrBadQuery)
+	assert.Equal(t, 1, attempts)
+
+	attempts = 0
+	err = policy.do(context.TODO(), querylog.NewNop().BeginQuery("test"), IsRetryable, func() error {
+		attempts++
+		return errors.New("boom")
+	})
+
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicy_StopsOnContextDone(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Hour,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var attempts int
+	err := policy.do(ctx, querylog.NewNop().BeginQuery("test"), IsRetryable, func() error {
+		attempts++
+		return NewError(http.StatusServiceUnavailable, "unavailable")
+	})
+
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+
+	err := NewError(http.StatusServiceUnavailable, "unavailable")
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1, err))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2, err))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(3, err))
+	assert.Equal(t, time.Second, policy.backoff(5, err))
+
+	// Jitter stays within bounds
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(1, err)
+		assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+		assert.LessOrEqual(t, delay, 100*time.Millisecond)
+	}
+}
+
+func TestRetryPolicy_HonorsRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	core, logs := observer.New(zap.InfoLevel)
+	c, err := NewClient(srv.URL,
+		WithQueryLog(zap.New(core), false),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+	require.NoError(t, err)
+
+	// The context ends long before the requested delay, so the query gives
+	// up after the first attempt rather than retrying early
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = c.LabelQuery().Do(ctx)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, int32(1), attempts.Load())
+
+	var delays []any
+	for _, entry := range logs.All() {
+		if delay, ok := entry.ContextMap()["retry_delay"]; ok {
+			delays = append(delays, delay)
+		}
+	}
+
+	assert.Equal(t, []any{2 * time.Second}, delays)
+}
+
+func TestRetryPolicy_CapsRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"status": "success", "data": []}`))
+	}))
+	defer srv.Close()
+
+	core, logs := observer.New(zap.InfoLevel)
+	c, err := NewClient(srv.URL,
+		WithQueryLog(zap.New(core), false),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, MaxBackoff: 10 * time.Millisecond}))
+	require.NoError(t, err)
+
+	// The day-long delay requested by the server is capped at MaxBackoff
+	_, err = c.LabelQuery().Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), attempts.Load())
+
+	var delays []any
+	for _, entry := range logs.All() {
+		if delay, ok := entry.ContextMap()["retry_delay"]; ok {
+			delays = append(delays, delay)
+		}
+	}
+
+	assert.Equal(t, []any{10 * time.Millisecond}, delays)
+}
@@ -0,0 +1,94 @@
+package prom
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+// RuleType filters rules by kind.
+type RuleType string
+
+// Rule types used for filtering.
+const (
+	RuleTypeAlerting  RuleType = "alert"
+	RuleTypeRecording RuleType = "record"
+)
+
+// A RuleGroup is a group of rules evaluated together.
+type RuleGroup struct {
+	Name           string    `json:"name" yaml:"name"`
+	File           string    `json:"file" yaml:"file"`
+	Interval       float64   `json:"interval" yaml:"interval"`
+	Rules          []Rule    `json:"rules" yaml:"rules"`
+	EvaluationTime float64   `json:"evaluationTime" yaml:"evaluationTime"`
+	LastEvaluation time.Time `json:"lastEvaluation" yaml:"lastEvaluation"`
+}
+
+// A Rule is an alerting or recording rule. Type is "alerting" or
+// "recording"; Duration, Annotations, Alerts and State are only set for
+// alerting rules.
+type Rule struct {
+	Type           string         `json:"type" yaml:"type"`
+	Name           string         `json:"name" yaml:"name"`
+	Query          string         `json:"query" yaml:"query"`
+	Labels         model.LabelSet `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Health         string         `json:"health" yaml:"health"`
+	LastError      string         `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+	EvaluationTime float64        `json:"evaluationTime" yaml:"evaluationTime"`
+	LastEvaluation time.Time      `json:"lastEvaluation" yaml:"lastEvaluation"`
+	Duration       float64        `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Annotations    model.LabelSet `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Alerts         []Alert        `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+	State          AlertState     `json:"state,omitempty" yaml:"state,omitempty"`
+}
+
+// A RulesQuery returns the alerting and recording rules loaded by the server.
+type RulesQuery interface {
+	Type(t RuleType) RulesQuery
+	Do(ctx context.Context) ([]RuleGroup, error)
+}
+
+func (c *client) Rules() RulesQuery {
+	return rulesQuery{
+		c: c,
+	}
+}
+
+type rulesQuery struct {
+	c        *client
+	ruleType RuleType
+}
+
+func (q rulesQuery) Type(t RuleType) RulesQuery {
+	q.ruleType = t
+	return q
+}
+
+func (q rulesQuery) Do(ctx context.Context) ([]RuleGroup, error) {
+	p := url.Values{}
+
+	if q.ruleType != "" {
+		p.Add("type", string(q.ruleType))
+	}
+
+	log := q.c.queryLog.BeginQuery("rules-query",
+		zap.String("type", string(q.ruleType)))
+
+	var r rulesResult
+	if err := q.c.get(ctx, log, pathRulesQuery, p, &r); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if err := envelopeErr(r.Status, r.ErrorType, r.Error); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	log.QueryComplete(&r)
+	return r.Data.Groups, nil
+}
@@ -6,7 +6,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/mmihic/httplib/src/pkg/httplib"
 	"github.com/prometheus/common/model"
 	"go.uber.org/zap"
 )
@@ -16,7 +15,15 @@ type SeriesQuery interface {
 	Start(t time.Time) SeriesQuery
 	End(t time.Time) SeriesQuery
 	Selectors(sel []string) SeriesQuery
+
+	// Limit sets the maximum number of series to return. If the limit is
+	// hit, the server reports a truncation warning.
+	Limit(n int) SeriesQuery
 	Do(ctx context.Context) ([]model.LabelSet, error)
+
+	// DoWithWarnings runs the query, also returning any warnings reported
+	// by the server.
+	DoWithWarnings(ctx context.Context) ([]model.LabelSet, Warnings, error)
 }
 
 func (c *client) SeriesQuery() SeriesQuery {
@@ -30,6 +37,7 @@ type seriesQuery struct {
 	sels  []string
 	start time.Time
 	end   time.Time
+	limit int
 }
 
 func (q seriesQuery) Selectors(sels []string) SeriesQuery {
@@ -47,15 +55,25 @@ func (q seriesQuery) End(t time.Time) SeriesQuery {
 	return q
 }
 
+func (q seriesQuery) Limit(n int) SeriesQuery {
+	q.limit = n
+	return q
+}
+
 func (q seriesQuery) Do(ctx context.Context) ([]model.LabelSet, error) {
+	series, _, err := q.DoWithWarnings(ctx)
+	return series, err
+}
+
+func (q seriesQuery) DoWithWarnings(ctx context.Context) ([]model.LabelSet, Warnings, error) {
 	p := url.Values{}
 
 	if !q.start.IsZero() {
-		p.Add("start", strconv.FormatInt(q.start.Unix(), 10))
+		p.Add("start", formatTime(q.start))
 	}
 
 	if !q.end.IsZero() {
-		p.Add("end", strconv.FormatInt(q.end.Unix(), 10))
+		p.Add("end", formatTime(q.end))
 	}
 
 	if len(q.sels) != 0 {
@@ -64,23 +82,27 @@ func (q seriesQuery) Do(ctx context.Context) ([]model.LabelSet, error) {
 		}
 	}
 
+	if q.limit != 0 {
+		p.Add("limit", strconv.Itoa(q.limit))
+	}
+
 	log := q.c.queryLog.BeginQuery("series-query",
 		zap.Strings("sels", q.sels),
 		zap.Time("start", q.start),
-		zap.Time("end", q.end))
+		zap.Time("end", q.end),
+		zap.Int("limit", q.limit))
 
 	var r seriesResult
-	if err := q.c.http.Post(ctx, pathSeriesQuery,
-		httplib.FormURLEncoded(p), httplib.JSON(&r)); err != nil {
+	if err := q.c.post(ctx, log, pathSeriesQuery, p, &r); err != nil {
 		log.QueryFailed(err)
+		return nil, nil, err
+	}
 
-		if httperr, ok := httplib.UnwrapError(err); ok {
-			return nil, NewError(httperr.StatusCode, httperr.Body.String())
-		}
-
-		return nil, err
+	if err := envelopeErr(r.Status, r.ErrorType, r.Error); err != nil {
+		log.QueryFailed(err)
+		return nil, nil, err
 	}
 
 	log.QueryComplete(&r)
-	return r.Data, nil
+	return r.Data, r.Warnings, nil
 }
@@ -0,0 +1,143 @@
+package prom
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// BuildInfo describes the build of the server.
+type BuildInfo struct {
+	Version   string `json:"version" yaml:"version"`
+	Revision  string `json:"revision" yaml:"revision"`
+	Branch    string `json:"branch" yaml:"branch"`
+	BuildUser string `json:"buildUser" yaml:"buildUser"`
+	BuildDate string `json:"buildDate" yaml:"buildDate"`
+	GoVersion string `json:"goVersion" yaml:"goVersion"`
+}
+
+// RuntimeInfo describes the runtime state of the server.
+type RuntimeInfo struct {
+	StartTime           time.Time `json:"startTime" yaml:"startTime"`
+	CWD                 string    `json:"CWD" yaml:"CWD"`
+	ReloadConfigSuccess bool      `json:"reloadConfigSuccess" yaml:"reloadConfigSuccess"`
+	LastConfigTime      time.Time `json:"lastConfigTime" yaml:"lastConfigTime"`
+	CorruptionCount     int64     `json:"corruptionCount" yaml:"corruptionCount"`
+	GoroutineCount      int       `json:"goroutineCount" yaml:"goroutineCount"`
+	GOMAXPROCS          int       `json:"GOMAXPROCS" yaml:"GOMAXPROCS"`
+	GOGC                string    `json:"GOGC" yaml:"GOGC"`
+	GODEBUG             string    `json:"GODEBUG" yaml:"GODEBUG"`
+	StorageRetention    string    `json:"storageRetention" yaml:"storageRetention"`
+}
+
+// TSDBStatus contains cardinality statistics for the server's TSDB head block.
+type TSDBStatus struct {
+	HeadStats                   TSDBHeadStats `json:"headStats" yaml:"headStats"`
+	SeriesCountByMetricName     []TSDBStat    `json:"seriesCountByMetricName" yaml:"seriesCountByMetricName"`
+	LabelValueCountByLabelName  []TSDBStat    `json:"labelValueCountByLabelName" yaml:"labelValueCountByLabelName"`
+	MemoryInBytesByLabelName    []TSDBStat    `json:"memoryInBytesByLabelName" yaml:"memoryInBytesByLabelName"`
+	SeriesCountByLabelValuePair []TSDBStat    `json:"seriesCountByLabelValuePair" yaml:"seriesCountByLabelValuePair"`
+}
+
+// TSDBHeadStats are summary statistics for the TSDB head block. MinTime
+// and MaxTime are in milliseconds since the epoch.
+type TSDBHeadStats struct {
+	NumSeries     uint64 `json:"numSeries" yaml:"numSeries"`
+	NumLabelPairs int    `json:"numLabelPairs" yaml:"numLabelPairs"`
+	ChunkCount    int64  `json:"chunkCount" yaml:"chunkCount"`
+	MinTime       int64  `json:"minTime" yaml:"minTime"`
+	MaxTime       int64  `json:"maxTime" yaml:"maxTime"`
+}
+
+// A TSDBStat is a single named count, e.g. the number of series for a
+// metric name.
+type TSDBStat struct {
+	Name  string `json:"name" yaml:"name"`
+	Value uint64 `json:"value" yaml:"value"`
+}
+
+// A TSDBStatusQuery returns cardinality statistics for the TSDB.
+type TSDBStatusQuery interface {
+	// Limit sets the number of entries returned in each list of
+	// statistics. The server defaults to 10.
+	Limit(n int) TSDBStatusQuery
+	Do(ctx context.Context) (*TSDBStatus, error)
+}
+
+func (c *client) BuildInfo(ctx context.Context) (*BuildInfo, error) {
+	return getStatus[BuildInfo](ctx, c, "build-info", pathBuildInfo, nil)
+}
+
+func (c *client) RuntimeInfo(ctx context.Context) (*RuntimeInfo, error) {
+	return getStatus[RuntimeInfo](ctx, c, "runtime-info", pathRuntimeInfo, nil)
+}
+
+func (c *client) Flags(ctx context.Context) (map[string]string, error) {
+	flags, err := getStatus[map[string]string](ctx, c, "flags", pathFlags, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return *flags, nil
+}
+
+func (c *client) Config(ctx context.Context) (string, error) {
+	config, err := getStatus[struct {
+		YAML string `json:"yaml"`
+	}](ctx, c, "config", pathConfig, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return config.YAML, nil
+}
+
+func (c *client) TSDBStatus() TSDBStatusQuery {
+	return tsdbStatusQuery{
+		c: c,
+	}
+}
+
+type tsdbStatusQuery struct {
+	c     *client
+	limit int
+}
+
+func (q tsdbStatusQuery) Limit(n int) TSDBStatusQuery {
+	q.limit = n
+	return q
+}
+
+func (q tsdbStatusQuery) Do(ctx context.Context) (*TSDBStatus, error) {
+	p := url.Values{}
+	if q.limit != 0 {
+		p.Add("limit", strconv.Itoa(q.limit))
+	}
+
+	return getStatus[TSDBStatus](ctx, q.c, "tsdb-status", pathTSDBStatus, p,
+		zap.Int("limit", q.limit))
+}
+
+// getStatus retrieves one of the /api/v1/status endpoints.
+func getStatus[T any](
+	ctx context.Context, c *client, queryType, path string, p url.Values, fields ...zap.Field,
+) (*T, error) {
+	log := c.queryLog.BeginQuery(queryType, fields...)
+
+	var r statusResult[T]
+	if err := c.get(ctx, log, path, p, &r); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if err := envelopeErr(r.Status, r.ErrorType, r.Error); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	log.QueryComplete(&r)
+	return &r.Data, nil
+}
@@ -0,0 +1,61 @@
+package prom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case pathBuildInfo:
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"version": "2.50.1", "goVersion": "go1.21.7"}}`))
+		case pathFlags:
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"query.timeout": "2m"}}`))
+		case pathConfig:
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"yaml": "global:\n  scrape_interval: 15s\n"}}`))
+		case pathTSDBStatus:
+			assert.Equal(t, "3", r.URL.Query().Get("limit"))
+			_, _ = w.Write([]byte(`{
+  "status": "success",
+  "data": {
+    "headStats": {"numSeries": 508, "chunkCount": 937},
+    "seriesCountByMetricName": [{"name": "up", "value": 20}],
+    "seriesCountByLabelValuePair": [{"name": "job=node", "value": 425}]
+  }
+}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	buildInfo, err := c.BuildInfo(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, &BuildInfo{Version: "2.50.1", GoVersion: "go1.21.7"}, buildInfo)
+
+	flags, err := c.Flags(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"query.timeout": "2m"}, flags)
+
+	config, err := c.Config(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "global:\n  scrape_interval: 15s\n", config)
+
+	tsdb, err := c.TSDBStatus().Limit(3).Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(508), tsdb.HeadStats.NumSeries)
+	assert.Equal(t, []TSDBStat{{Name: "up", Value: 20}}, tsdb.SeriesCountByMetricName)
+	assert.Equal(t, []TSDBStat{{Name: "job=node", Value: 425}}, tsdb.SeriesCountByLabelValuePair)
+
+	_, err = c.RuntimeInfo(context.TODO())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,115 @@
+package prom
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+// TargetState filters targets by whether they are being scraped.
+type TargetState string
+
+// Target states.
+const (
+	TargetStateActive  TargetState = "active"
+	TargetStateDropped TargetState = "dropped"
+	TargetStateAny     TargetState = "any"
+)
+
+// TargetHealth is the health of an active target as of its last scrape.
+type TargetHealth string
+
+// Target health values.
+const (
+	TargetHealthUp      TargetHealth = "up"
+	TargetHealthDown    TargetHealth = "down"
+	TargetHealthUnknown TargetHealth = "unknown"
+)
+
+// Targets are the scrape targets known to the server.
+type Targets struct {
+	Active  []ActiveTarget  `json:"activeTargets" yaml:"activeTargets"`
+	Dropped []DroppedTarget `json:"droppedTargets" yaml:"droppedTargets"`
+}
+
+// An ActiveTarget is a target that is being scraped.
+type ActiveTarget struct {
+	DiscoveredLabels   map[string]string `json:"discoveredLabels" yaml:"discoveredLabels"`
+	Labels             model.LabelSet    `json:"labels" yaml:"labels"`
+	ScrapePool         string            `json:"scrapePool" yaml:"scrapePool"`
+	ScrapeURL          string            `json:"scrapeUrl" yaml:"scrapeUrl"`
+	GlobalURL          string            `json:"globalUrl" yaml:"globalUrl"`
+	LastError          string            `json:"lastError" yaml:"lastError"`
+	LastScrape         time.Time         `json:"lastScrape" yaml:"lastScrape"`
+	LastScrapeDuration float64           `json:"lastScrapeDuration" yaml:"lastScrapeDuration"`
+	Health             TargetHealth      `json:"health" yaml:"health"`
+	ScrapeInterval     string            `json:"scrapeInterval" yaml:"scrapeInterval"`
+	ScrapeTimeout      string            `json:"scrapeTimeout" yaml:"scrapeTimeout"`
+}
+
+// A DroppedTarget is a target that was discovered but dropped by relabelling.
+type DroppedTarget struct {
+	DiscoveredLabels map[string]string `json:"discoveredLabels" yaml:"discoveredLabels"`
+}
+
+// A TargetsQuery returns the scrape targets known to the server.
+type TargetsQuery interface {
+	State(state TargetState) TargetsQuery
+	ScrapePool(pool string) TargetsQuery
+	Do(ctx context.Context) (*Targets, error)
+}
+
+func (c *client) Targets() TargetsQuery {
+	return targetsQuery{
+		c: c,
+	}
+}
+
+type targetsQuery struct {
+	c          *client
+	state      TargetState
+	scrapePool string
+}
+
+func (q targetsQuery) State(state TargetState) TargetsQuery {
+	q.state = state
+	return q
+}
+
+func (q targetsQuery) ScrapePool(pool string) TargetsQuery {
+	q.scrapePool = pool
+	return q
+}
+
+func (q targetsQuery) Do(ctx context.Context) (*Targets, error) {
+	p := url.Values{}
+
+	if q.state != "" {
+		p.Add("state", string(q.state))
+	}
+
+	if q.scrapePool != "" {
+		p.Add("scrapePool", q.scrapePool)
+	}
+
+	log := q.c.queryLog.BeginQuery("targets-query",
+		zap.String("state", string(q.state)),
+		zap.String("scrape_pool", q.scrapePool))
+
+	var r targetsResult
+	if err := q.c.get(ctx, log, pathTargetsQuery, p, &r); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	if err := envelopeErr(r.Status, r.ErrorType, r.Error); err != nil {
+		log.QueryFailed(err)
+		return nil, err
+	}
+
+	log.QueryComplete(&r)
+	return &r.Data, nil
+}
@@ -0,0 +1,64 @@
+package prom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetsQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, pathTargetsQuery, r.URL.Path)
+		assert.Equal(t, "active", r.URL.Query().Get("state"))
+		assert.Equal(t, "node", r.URL.Query().Get("scrapePool"))
+
+		_, _ = w.Write([]byte(`{
+  "status": "success",
+  "data": {
+    "activeTargets": [
+      {"scrapePool": "node", "scrapeUrl": "http://host-1:9100/metrics", "health": "down", "lastError": "connection refused"}
+    ],
+    "droppedTargets": []
+  }
+}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	r, err := c.Targets().State(TargetStateActive).ScrapePool("node").Do(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, r.Active, 1)
+	assert.Equal(t, TargetHealthDown, r.Active[0].Health)
+	assert.Equal(t, "connection refused", r.Active[0].LastError)
+}
+
+func TestAlertsQuery_FiltersByState(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, pathAlertsQuery, r.URL.Path)
+		_, _ = w.Write([]byte(`{
+  "status": "success",
+  "data": {
+    "alerts": [
+      {"labels": {"alertname": "A"}, "state": "firing", "value": "1"},
+      {"labels": {"alertname": "B"}, "state": "pending", "value": "2"}
+    ]
+  }
+}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	r, err := c.Alerts().State(AlertStateFiring).Do(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, r, 1)
+	assert.Equal(t, "1", r[0].Value)
+}
@@ -0,0 +1,162 @@
+package prom
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSFiles are file-based TLS settings. The files are re-read whenever
+// they change, so that rotated certificates are picked up without
+// restarting.
+type TLSFiles struct {
+	// CAFile is a PEM bundle of CAs used to verify the server, in place of
+	// the system roots.
+	CAFile string
+
+	// CertFile and KeyFile are the PEM client certificate and key used for
+	// mutual TLS.
+	CertFile string
+	KeyFile  string
+
+	// ServerName overrides the name used to verify the server certificate.
+	ServerName string
+
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the server.
+//
+// The configuration applies to every request, including those that bypass
+// httplib, so it cannot be combined with WithHTTPClient or WithHTTPOptions,
+// whose queries would not use it.
+func WithTLSConfig(cfg *tls.Config) ClientOpt {
+	return func(c *client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithTLSFiles loads the TLS configuration used to connect to the server
+// from files, reloading them when they change. As with WithTLSConfig, it
+// cannot be combined with WithHTTPClient or WithHTTPOptions.
+func WithTLSFiles(files TLSFiles) ClientOpt {
+	return func(c *client) {
+		c.tlsFiles = &files
+	}
+}
+
+// newTLSTransport returns a transport for the given static TLS config.
+func newTLSTransport(cfg *tls.Config) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = cfg.Clone()
+	return t
+}
+
+// reloadingTransport is a RoundTripper that rebuilds its transport when
+// any of its TLS files change.
+type reloadingTransport struct {
+	files TLSFiles
+
+	mut      sync.Mutex
+	modTimes [3]time.Time
+	current  *http.Transport
+}
+
+// newReloadingTransport returns a transport for the given TLS files,
+// failing if they cannot be loaded.
+func newReloadingTransport(files TLSFiles) (*reloadingTransport, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, errors.New("both or neither of the TLS certificate and key files must be set")
+	}
+
+	t := &reloadingTransport{files: files}
+	if _, err := t.transport(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *reloadingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt, err := t.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	return rt.RoundTrip(req)
+}
+
+// transport returns the current transport, rebuilding it if any of the
+// files have been modified since it was built. If reloading fails, the
+// error is returned rather than continuing with stale credentials.
+func (t *reloadingTransport) transport() (*http.Transport, error) {
+	var modTimes [3]time.Time
+	for i, path := range []string{t.files.CAFile, t.files.CertFile, t.files.KeyFile} {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		modTimes[i] = info.ModTime()
+	}
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if t.current != nil && modTimes == t.modTimes {
+		return t.current, nil
+	}
+
+	cfg, err := t.files.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.current != nil {
+		t.current.CloseIdleConnections()
+	}
+
+	t.current, t.modTimes = newTLSTransport(cfg), modTimes
+	return t.current, nil
+}
+
+// load builds a TLS config from the files.
+func (files TLSFiles) load() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         files.ServerName,
+		InsecureSkipVerify: files.InsecureSkipVerify,
+	}
+
+	if files.CAFile != "" {
+		b, err := os.ReadFile(files.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA file: %w", err)
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", files.CAFile)
+		}
+	}
+
+	if files.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
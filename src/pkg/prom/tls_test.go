@@ -0,0 +1,200 @@
+package prom
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mmihic/httplib/src/pkg/httplib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(labelsHandler())
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	c, err := NewClient(srv.URL, WithTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}))
+	require.NoError(t, err)
+
+	labels, err := c.LabelQuery().Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job"}, labels)
+
+	// Without the CA the server is not trusted
+	c, err = NewClient(srv.URL, WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	require.NoError(t, err)
+
+	_, err = c.LabelQuery().Do(context.TODO())
+	assert.ErrorContains(t, err, "certificate")
+
+	_, err = NewClient(srv.URL,
+		WithTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}),
+		WithHTTPOptions(httplib.SetHeader("X-Test", "true")))
+	assert.ErrorContains(t, err, "WithHTTPOptions cannot be combined with TLS settings")
+
+	httpc, err := httplib.NewClient(srv.URL)
+	require.NoError(t, err)
+
+	_, err = NewClient(srv.URL,
+		WithTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}),
+		WithHTTPClient(httpc))
+	assert.ErrorContains(t, err, "WithHTTPClient cannot be combined with TLS settings")
+}
+
+func TestWithTLSFiles_MutualTLS(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		ca       = newTestCA(t)
+		caFile   = filepath.Join(dir, "ca.pem")
+		certFile = filepath.Join(dir, "client.pem")
+		keyFile  = filepath.Join(dir, "client-key.pem")
+		seen     []string
+	)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.TLS.PeerCertificates[0].Subject.CommonName)
+		labelsHandler().ServeHTTP(w, r)
+	}))
+	srv.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  ca.pool(),
+		MinVersion: tls.VersionTLS12,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	writePEM(t, caFile, "CERTIFICATE", srv.Certificate().Raw)
+	ca.issue(t, "client-1", certFile, keyFile)
+
+	c, err := NewClient(srv.URL, WithTLSFiles(TLSFiles{
+		CAFile:     caFile,
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		ServerName: "example.com",
+	}))
+	require.NoError(t, err)
+
+	_, err = c.LabelQuery().Do(context.TODO())
+	require.NoError(t, err)
+
+	// Rotating the client certificate is picked up by the next request
+	ca.issue(t, "client-2", certFile, keyFile)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	_, err = c.LabelQuery().Do(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client-1", "client-2"}, seen)
+}
+
+func TestWithTLSFiles_Errors(t *testing.T) {
+	dir := t.TempDir()
+	badCA := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(badCA, []byte("not a certificate"), 0o600))
+
+	for _, tt := range []struct {
+		name        string
+		files       TLSFiles
+		expectedErr string
+	}{
+		{"missing CA file", TLSFiles{CAFile: filepath.Join(dir, "missing.pem")}, "no such file"},
+		{"invalid CA file", TLSFiles{CAFile: badCA}, "no certificates found"},
+		{"cert without key", TLSFiles{CertFile: badCA}, "both or neither"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient("https://localhost", WithTLSFiles(tt.files))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+
+	_, err := NewClient("https://localhost",
+		WithTLSFiles(TLSFiles{InsecureSkipVerify: true}),
+		WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	assert.ErrorContains(t, err, "only one of")
+}
+
+func labelsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status": "success", "data": ["job"]}`))
+	})
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue writes a client certificate signed by the CA.
+func (ca *testCA) issue(t *testing.T, name, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	b := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+}
@@ -21,6 +21,13 @@ type ValueIter interface {
 
 	// StringValue returns the value of the current element as a string.
 	StringValue() string
+
+	// IsHistogram returns true if the current element is a native histogram.
+	IsHistogram() bool
+
+	// HistogramValue returns the value of the current element if it is a
+	// native histogram, or nil otherwise.
+	HistogramValue() *model.SampleHistogram
 }
 
 // NewValueIter returns a ValueIter around the given value.
@@ -61,25 +68,48 @@ func (iter *emptyIter) StringValue() string {
 	return ""
 }
 
+func (iter *emptyIter) IsHistogram() bool {
+	return false
+}
+
+func (iter *emptyIter) HistogramValue() *model.SampleHistogram {
+	return nil
+}
+
 type matrixIter struct {
 	m               model.Matrix
-	i, j            int
+	i, j, k         int
 	curSampleStream *model.SampleStream
 	curSamplePair   model.SamplePair
+	curHistogram    *model.SampleHistogramPair
 }
 
 func (iter *matrixIter) Next() bool {
 	for iter.i < len(iter.m) {
 		iter.curSampleStream = iter.m[iter.i]
-		if iter.j >= len(iter.curSampleStream.Values) {
+		var (
+			values     = iter.curSampleStream.Values
+			histograms = iter.curSampleStream.Histograms
+		)
+
+		if iter.j >= len(values) && iter.k >= len(histograms) {
 			// We're past the end of the current sample stream, move to the next
 			iter.i++
-			iter.j = 0
+			iter.j, iter.k = 0, 0
 			continue
 		}
 
-		iter.curSamplePair = iter.curSampleStream.Values[iter.j]
-		iter.j++
+		// Interleave float and histogram samples in timestamp order
+		if iter.k >= len(histograms) ||
+			(iter.j < len(values) && values[iter.j].Timestamp <= histograms[iter.k].Timestamp) {
+			iter.curSamplePair = values[iter.j]
+			iter.curHistogram = nil
+			iter.j++
+			return true
+		}
+
+		iter.curHistogram = &histograms[iter.k]
+		iter.k++
 		return true
 	}
 
@@ -92,17 +122,41 @@ func (iter *matrixIter) Metric() model.Metric {
 }
 
 func (iter *matrixIter) Timestamp() time.Time {
+	if iter.curHistogram != nil {
+		return iter.curHistogram.Timestamp.Time()
+	}
+
 	return iter.curSamplePair.Timestamp.Time()
 }
 
 func (iter *matrixIter) FloatValue() float64 {
+	if iter.curHistogram != nil {
+		return 0
+	}
+
 	return float64(iter.curSamplePair.Value)
 }
 
 func (iter *matrixIter) StringValue() string {
+	if iter.curHistogram != nil {
+		return iter.curHistogram.Histogram.String()
+	}
+
 	return iter.curSamplePair.Value.String()
 }
 
+func (iter *matrixIter) IsHistogram() bool {
+	return iter.curHistogram != nil
+}
+
+func (iter *matrixIter) HistogramValue() *model.SampleHistogram {
+	if iter.curHistogram == nil {
+		return nil
+	}
+
+	return iter.curHistogram.Histogram
+}
+
 type vectorIter struct {
 	v         model.Vector
 	i         int
@@ -128,13 +182,29 @@ func (iter *vectorIter) Timestamp() time.Time {
 }
 
 func (iter *vectorIter) FloatValue() float64 {
+	if iter.curSample.Histogram != nil {
+		return 0
+	}
+
 	return float64(iter.curSample.Value)
 }
 
 func (iter *vectorIter) StringValue() string {
+	if iter.curSample.Histogram != nil {
+		return iter.curSample.Histogram.String()
+	}
+
 	return iter.curSample.Value.String()
 }
 
+func (iter *vectorIter) IsHistogram() bool {
+	return iter.curSample.Histogram != nil
+}
+
+func (iter *vectorIter) HistogramValue() *model.SampleHistogram {
+	return iter.curSample.Histogram
+}
+
 type scalarIter struct {
 	s        *model.Scalar
 	consumed bool
@@ -165,6 +235,14 @@ func (iter *scalarIter) StringValue() string {
 	return iter.s.Value.String()
 }
 
+func (iter *scalarIter) IsHistogram() bool {
+	return false
+}
+
+func (iter *scalarIter) HistogramValue() *model.SampleHistogram {
+	return nil
+}
+
 type stringIter struct {
 	s        *model.String
 	consumed bool
@@ -195,6 +273,14 @@ func (iter *stringIter) StringValue() string {
 	return iter.s.Value
 }
 
+func (iter *stringIter) IsHistogram() bool {
+	return false
+}
+
+func (iter *stringIter) HistogramValue() *model.SampleHistogram {
+	return nil
+}
+
 var (
 	_ ValueIter = &matrixIter{}
 	_ ValueIter = &scalarIter{}
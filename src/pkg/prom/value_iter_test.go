@@ -196,3 +196,68 @@ func TestValueIter_Empty(t *testing.T) {
 func mustParseTime(s string) model.Time {
 	return model.Time(timex.MustParseTime(time.RFC3339, s).UnixMilli())
 }
+
+func TestValueIter_Matrix_Histograms(t *testing.T) {
+	h := &model.SampleHistogram{
+		Count: 4,
+		Sum:   3.5,
+		Buckets: model.HistogramBuckets{
+			{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 3},
+			{Boundaries: 0, Lower: 1, Upper: 2, Count: 1},
+		},
+	}
+
+	iter := NewValueIter(model.Matrix{
+		{
+			Metric: model.Metric{"foo": "bar"},
+			Values: []model.SamplePair{
+				{Timestamp: mustParseTime("2022-05-19T13:45:16Z"), Value: 3.145},
+				{Timestamp: mustParseTime("2022-05-19T13:47:16Z"), Value: 4.26},
+			},
+			Histograms: []model.SampleHistogramPair{
+				{Timestamp: mustParseTime("2022-05-19T13:46:16Z"), Histogram: h},
+			},
+		},
+	})
+
+	require.True(t, iter.Next())
+	require.False(t, iter.IsHistogram())
+	require.Nil(t, iter.HistogramValue())
+	require.Equal(t, 3.145, iter.FloatValue())
+
+	require.True(t, iter.Next())
+	require.True(t, iter.IsHistogram())
+	require.Equal(t, h, iter.HistogramValue())
+	require.Equal(t, "2022-05-19T13:46:16Z", iter.Timestamp().UTC().Format(time.RFC3339))
+	require.Equal(t, h.String(), iter.StringValue())
+
+	require.True(t, iter.Next())
+	require.False(t, iter.IsHistogram())
+	require.Equal(t, 4.26, iter.FloatValue())
+
+	require.False(t, iter.Next())
+}
+
+func TestValueIter_Vector_Histogram(t *testing.T) {
+	h := &model.SampleHistogram{
+		Count: 1,
+		Sum:   0.75,
+		Buckets: model.HistogramBuckets{
+			{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 1},
+		},
+	}
+
+	iter := NewValueIter(model.Vector{
+		{
+			Metric:    model.Metric{"foo": "bar"},
+			Timestamp: mustParseTime("2022-05-19T13:45:16Z"),
+			Histogram: h,
+		},
+	})
+
+	require.True(t, iter.Next())
+	require.True(t, iter.IsHistogram())
+	require.Equal(t, h, iter.HistogramValue())
+	require.Equal(t, float64(0), iter.FloatValue())
+	require.False(t, iter.Next())
+}